@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -14,6 +15,10 @@ var (
 	addDue         string
 	addPriority    int
 	addDescription string
+	addRepeat      string
+	addParent      int64
+	addProject     string
+	addRaw         bool
 )
 
 var addCmd = &cobra.Command{
@@ -21,47 +26,124 @@ var addCmd = &cobra.Command{
 	Short: "Add a new todo",
 	Long: `Add a new todo item with optional tags, due date, and priority.
 
+The title can carry them itself: #tag adds a tag, !1 to !5 set the priority,
++name the project, and a due date at the end of the title sets the due date,
+as in "Ship release notes tomorrow 3pm #work !2 +website". What was read from
+the title is printed after the todo is created. --due, --priority and
+--project take precedence: with them, that part stays in the title. Tags from
+--tags are added to those in the title, and --raw keeps the title as typed.
+
 Examples:
   todo add "Buy groceries"
+  todo add "Ship release notes tomorrow 3pm #work !2 +website"
+  todo add "Fix #123 for good" --raw
   todo add "Finish report" --tags "#work #urgent"
   todo add "Call mom" --due 2026-02-14
+  todo add "Dentist" --due "2026-02-14 14:30"
+  todo add "Standup notes" --due "tomorrow 9am"
   todo add "Important task" --priority 1
-  todo add "Project task" --tags "#work" --due tomorrow --priority 2`,
+  todo add "Project task" --tags "#work" --due tomorrow --priority 2
+  todo add "Standup" --due tomorrow --repeat "every weekday"
+  todo add "Pay rent" --due 2026-03-01 --repeat "monthly on the 1st"
+  todo add "Write tests" --parent 12
+  todo add "Fix footer" --project website`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		title := args[0]
+		todo, err := storage.ParseQuickAdd(args[0], storage.QuickAddOptions{
+			Raw:          addRaw,
+			KeepDue:      addDue != "",
+			KeepPriority: cmd.Flags().Changed("priority"),
+			KeepProject:  addProject != "",
+		})
+		if err != nil {
+			return err
+		}
+		quick := quickAddSummary(todo)
 
-		todo := &model.Todo{
-			Title:       title,
-			Description: addDescription,
-			Tags:        storage.ParseTags(addTags),
-			Priority:    addPriority,
+		todo.Description = addDescription
+		todo.Tags = storage.ParseTags(settings.Add.Tags + " " + strings.Join(todo.Tags, " ") + " " + addTags)
+		if cmd.Flags().Changed("priority") {
+			todo.Priority = addPriority
 		}
 
 		if addDue != "" {
-			dueDate, err := storage.ParseDueDate(addDue)
+			dueDate, hasTime, err := storage.ParseDue(addDue)
 			if err != nil {
-				return fmt.Errorf("invalid due date: %w", err)
+				return storage.Errorf(storage.ErrInvalid, "invalid due date: %w", err)
 			}
 			todo.DueDate = dueDate
+			todo.DueHasTime = hasTime
 		}
 
-		if addPriority < 0 || addPriority > 5 {
-			return fmt.Errorf("priority must be between 0 and 5 (1=highest, 5=lowest, 0=none)")
+		if addRepeat != "" {
+			rule, err := model.ParseRecurrence(addRepeat)
+			if err != nil {
+				return storage.Errorf(storage.ErrInvalid, "invalid repeat rule: %w", err)
+			}
+			todo.Recurrence = rule.String()
+		}
+
+		if cmd.Flags().Changed("parent") {
+			todo.ParentID = &addParent
+		}
+
+		if addProject != "" {
+			todo.Project = addProject
+		}
+		if todo.Project != "" {
+			projectID, err := resolveProject(todo.Project)
+			if err != nil {
+				return err
+			}
+			todo.ProjectID = projectID
+		}
+
+		if todo.Priority < 0 || todo.Priority > 5 {
+			return storage.Errorf(storage.ErrInvalid, "priority must be between 0 and 5 (1=highest, 5=lowest, 0=none)")
 		}
 
 		if err := store.Create(todo); err != nil {
 			return fmt.Errorf("failed to create todo: %w", err)
 		}
 
-		fmt.Printf("Created todo #%d: %s\n", todo.ID, todo.Title)
+		if todo.ParentID != nil {
+			fmt.Printf("Created subtask #%d of #%d: %s\n", todo.ID, *todo.ParentID, todo.Title)
+		} else {
+			fmt.Printf("Created todo #%d: %s\n", todo.ID, todo.Title)
+		}
+		if quick != "" {
+			fmt.Printf("  from title: %s\n", quick)
+		}
 		return nil
 	},
 }
 
+// quickAddSummary describes what ParseQuickAdd read from a title besides
+// the title itself, or returns "" if there was nothing
+func quickAddSummary(todo *model.Todo) string {
+	var parts []string
+	if todo.DueDate != nil {
+		parts = append(parts, "due "+todo.FormatDue(dateLayout()))
+	}
+	if len(todo.Tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(todo.Tags, " "))
+	}
+	if todo.Priority > 0 {
+		parts = append(parts, fmt.Sprintf("priority %d", todo.Priority))
+	}
+	if todo.Project != "" {
+		parts = append(parts, "project "+todo.Project)
+	}
+	return strings.Join(parts, ", ")
+}
+
 func init() {
 	addCmd.Flags().StringVarP(&addTags, "tags", "t", "", "Tags (e.g., '#work #urgent')")
-	addCmd.Flags().StringVarP(&addDue, "due", "d", "", "Due date (e.g., '2026-02-14', 'today', 'tomorrow')")
+	addCmd.Flags().StringVarP(&addDue, "due", "d", "", "Due date, optionally with a time (e.g., '2026-02-14', 'tomorrow 9am', 'next friday'; see 'todo date parse --help')")
 	addCmd.Flags().IntVarP(&addPriority, "priority", "p", 0, "Priority (1=highest, 5=lowest, 0=none)")
 	addCmd.Flags().StringVar(&addDescription, "desc", "", "Description")
+	addCmd.Flags().StringVar(&addProject, "project", "", "Project name")
+	addCmd.Flags().Int64Var(&addParent, "parent", 0, "Parent todo ID (creates a subtask)")
+	addCmd.Flags().BoolVar(&addRaw, "raw", false, "Use the title as typed, without reading #tags, !priority, +project or a due date from it")
+	addCmd.Flags().StringVarP(&addRepeat, "repeat", "r", "", "Repeat rule (e.g., 'every weekday', 'every 2 weeks on monday', 'FREQ=DAILY')")
 }
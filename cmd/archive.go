@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var (
+	archiveCompletedBefore string
+	archiveAuto            string
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Archive completed todos",
+	Long: `Move completed todos out of the active working set. Archived todos are
+hidden from 'todo list' and the TUI, but still show up in 'todo search' and
+in 'todo list --include-archived'. Reopening an archived todo unarchives it.
+
+With --auto, completed todos are archived automatically once they have been
+done for longer than the given age.
+
+Examples:
+  todo archive --completed-before 2026-01-01
+  todo archive --completed-before 90d
+  todo archive --auto 30d       # Archive todos completed over 30 days ago
+  todo archive --auto off       # Stop auto-archiving
+  todo archive                  # Show the auto-archive setting`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if archiveAuto != "" {
+			value := strings.ToLower(strings.TrimSpace(archiveAuto))
+			if value == "off" || value == "never" {
+				value = ""
+			} else if _, ok := storage.ParseAge(value); !ok {
+				return storage.Errorf(storage.ErrInvalid, "invalid --auto %q (use an age like 30d or 8w, or off)", archiveAuto)
+			}
+			if err := store.SetSetting(storage.SettingAutoArchive, value); err != nil {
+				return err
+			}
+			if value == "" {
+				fmt.Println("Auto-archive is off")
+			} else {
+				fmt.Printf("Completed todos will be archived after %s\n", value)
+				if err := autoArchive(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if archiveCompletedBefore != "" {
+			before, err := parseSince(archiveCompletedBefore, time.Now())
+			if err != nil {
+				return err
+			}
+			n, err := store.Archive(before)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Archived %d todo(s) completed before %s\n", n, before.Format("2006-01-02 15:04"))
+		}
+
+		if archiveAuto == "" && archiveCompletedBefore == "" {
+			after, err := store.Setting(storage.SettingAutoArchive)
+			if err != nil {
+				return err
+			}
+			if after == "" {
+				fmt.Println("Auto-archive is off. Archive with --completed-before or enable it with --auto 30d.")
+			} else {
+				fmt.Printf("Completed todos are archived after %s\n", after)
+			}
+		}
+		return nil
+	},
+}
+
+// autoArchive archives todos completed longer ago than the configured age
+func autoArchive() error {
+	after, err := store.Setting(storage.SettingAutoArchive)
+	if err != nil || after == "" {
+		return err
+	}
+
+	age, ok := storage.ParseAge(after)
+	if !ok {
+		return storage.Errorf(storage.ErrInvalid, "invalid auto-archive age %q (reset it with 'todo archive --auto')", after)
+	}
+
+	if _, err := store.AutoArchive(age.Before(time.Now())); err != nil {
+		return fmt.Errorf("failed to auto-archive: %w", err)
+	}
+	return nil
+}
+
+func init() {
+	archiveCmd.Flags().StringVar(&archiveCompletedBefore, "completed-before", "", "Archive todos completed before a date or age (2026-01-01, 90d)")
+	archiveCmd.Flags().StringVar(&archiveAuto, "auto", "", "Archive completed todos automatically after an age (30d), or off")
+}
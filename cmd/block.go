@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var (
+	blockOn    string
+	unblockOn  string
+	unblockAll bool
+)
+
+var blockCmd = &cobra.Command{
+	Use:   "block <id> --on <ids>",
+	Short: "Mark a todo as blocked by other todos",
+	Long: `Record that a todo cannot start until other todos are done.
+Dependencies that would form a cycle are rejected.
+
+Examples:
+  todo block 12 --on 7
+  todo block 12 --on 7,9`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return storage.Errorf(storage.ErrInvalid, "invalid ID: %s", args[0])
+		}
+
+		blockers, err := parseIDList(blockOn)
+		if err != nil {
+			return storage.Errorf(storage.ErrInvalid, "invalid --on: %w", err)
+		}
+
+		for _, blockerID := range blockers {
+			if err := store.AddDependency(id, blockerID); err != nil {
+				return err
+			}
+			fmt.Printf("Todo #%d is now blocked by #%d\n", id, blockerID)
+		}
+		return nil
+	},
+}
+
+var unblockCmd = &cobra.Command{
+	Use:   "unblock <id> [--on <ids> | --all]",
+	Short: "Remove blockers from a todo",
+	Long: `Remove dependencies from a todo.
+
+Examples:
+  todo unblock 12 --on 7
+  todo unblock 12 --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return storage.Errorf(storage.ErrInvalid, "invalid ID: %s", args[0])
+		}
+
+		var blockers []int64
+		switch {
+		case unblockAll:
+			todos, err := store.Blockers(id)
+			if err != nil {
+				return err
+			}
+			for _, t := range todos {
+				blockers = append(blockers, t.ID)
+			}
+			if len(blockers) == 0 {
+				fmt.Printf("Todo #%d has no blockers.\n", id)
+				return nil
+			}
+		case unblockOn != "":
+			blockers, err = parseIDList(unblockOn)
+			if err != nil {
+				return storage.Errorf(storage.ErrInvalid, "invalid --on: %w", err)
+			}
+		default:
+			return fmt.Errorf("specify blockers with --on or use --all")
+		}
+
+		for _, blockerID := range blockers {
+			if err := store.RemoveDependency(id, blockerID); err != nil {
+				return err
+			}
+			fmt.Printf("Todo #%d is no longer blocked by #%d\n", id, blockerID)
+		}
+		return nil
+	},
+}
+
+// parseIDList parses a comma-separated list of todo IDs like "7,9"
+func parseIDList(s string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "#")
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, storage.Errorf(storage.ErrInvalid, "invalid ID: %s", part)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no IDs given")
+	}
+	return ids, nil
+}
+
+func init() {
+	blockCmd.Flags().StringVar(&blockOn, "on", "", "IDs of the blocking todos (comma-separated)")
+	blockCmd.MarkFlagRequired("on")
+
+	unblockCmd.Flags().StringVar(&unblockOn, "on", "", "IDs of the blockers to remove (comma-separated)")
+	unblockCmd.Flags().BoolVar(&unblockAll, "all", false, "Remove all blockers")
+}
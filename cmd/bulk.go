@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/query"
+	"todo_cli/internal/storage"
+)
+
+var (
+	bulkFilterTag     string
+	bulkFilterProject string
+	bulkQuery         string
+	bulkDryRun        bool
+)
+
+// errDryRun rolls back the transaction of a --dry-run
+var errDryRun = errors.New("dry run")
+
+// addBulkFlags registers the flags that select todos by filter instead of by
+// ID, shared by complete, reopen, delete and edit
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&bulkFilterTag, "filter-tag", "", "Select todos with these tags instead of by ID")
+	cmd.Flags().StringVar(&bulkFilterProject, "filter-project", "", "Select todos in this project instead of by ID")
+	cmd.Flags().StringVarP(&bulkQuery, "query", "q", "", "Select todos matching a query expression instead of by ID")
+	cmd.Flags().BoolVar(&bulkDryRun, "dry-run", false, "Show what would change without changing anything")
+}
+
+// parseIDs parses ID arguments such as "3", "1,4,7-12" or "1 4 7-12"
+func parseIDs(args []string) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	add := func(id int64) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, arg := range args {
+		for _, part := range strings.Split(arg, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			lo, hi, isRange := strings.Cut(part, "-")
+			from, err := strconv.ParseInt(lo, 10, 64)
+			if err != nil || from <= 0 {
+				return nil, storage.Errorf(storage.ErrInvalid, "invalid ID: %s", part)
+			}
+			if !isRange {
+				add(from)
+				continue
+			}
+
+			to, err := strconv.ParseInt(hi, 10, 64)
+			if err != nil || to < from {
+				return nil, storage.Errorf(storage.ErrInvalid, "invalid ID range: %s", part)
+			}
+			if to-from >= 10000 {
+				return nil, storage.Errorf(storage.ErrInvalid, "ID range too large: %s", part)
+			}
+			for id := from; id <= to; id++ {
+				add(id)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// selectBulk resolves the todos a bulk command acts on, from ID arguments or
+// from the filter flags. completed narrows a filter selection to completed
+// or pending todos unless the query itself asks about status.
+func selectBulk(args []string, completed *bool) ([]model.Todo, error) {
+	byFilter := bulkFilterTag != "" || bulkFilterProject != "" || bulkQuery != ""
+	if len(args) > 0 && byFilter {
+		return nil, storage.Errorf(storage.ErrInvalid, "select todos either by ID or with --filter-tag, --filter-project or -q, not both")
+	}
+
+	if !byFilter {
+		if len(args) == 0 {
+			return nil, storage.Errorf(storage.ErrInvalid, "no todos selected: give IDs (e.g. 1,4,7-12) or --filter-tag, --filter-project or -q")
+		}
+		ids, err := parseIDs(args)
+		if err != nil {
+			return nil, err
+		}
+		todos := make([]model.Todo, 0, len(ids))
+		for _, id := range ids {
+			todo, err := store.GetByID(id)
+			if err != nil {
+				return nil, err
+			}
+			todos = append(todos, *todo)
+		}
+		return todos, nil
+	}
+
+	filter := storage.Filter{
+		Completed: completed,
+		Tags:      storage.ParseTags(bulkFilterTag),
+		Query:     bulkQuery,
+		SortBy:    storage.SortByCreated,
+		SortOrder: storage.SortAsc,
+	}
+	if bulkQuery != "" {
+		expr, err := query.Parse(bulkQuery)
+		if err != nil {
+			return nil, queryError(err)
+		}
+		if query.Uses(expr, query.FieldStatus) || query.Uses(expr, query.FieldCompleted) {
+			filter.Completed = nil
+		}
+	}
+	if bulkFilterProject != "" {
+		if _, err := store.GetProject(bulkFilterProject); err != nil {
+			return nil, err
+		}
+		filter.Project = bulkFilterProject
+	}
+
+	todos, err := store.List(filter)
+	if err != nil {
+		return nil, queryError(err)
+	}
+	return todos, nil
+}
+
+// bulkAction describes a bulk command for its summary
+type bulkAction struct {
+	verb string // "complete"
+	past string // "Completed"
+}
+
+// bulkOutcome is what a bulk command did to one todo
+type bulkOutcome struct {
+	skipped bool
+	reason  string // why a todo was skipped
+	extra   string // printed on its own line below the todo
+}
+
+// runBulk applies fn to every todo in a single transaction, so that either
+// all of them change or none do, and prints a per-item summary. With
+// --dry-run the transaction is rolled back after printing.
+func runBulk(action bulkAction, todos []model.Todo, fn func(tx storage.Storage, todo *model.Todo) (bulkOutcome, error)) error {
+	if len(todos) == 0 {
+		fmt.Println("No matching todos.")
+		return nil
+	}
+
+	var lines []string
+	changed, skipped := 0, 0
+
+	// A single todo's operation keeps its own name, e.g. complete #3 "title"
+	description := ""
+	if len(todos) > 1 {
+		description = fmt.Sprintf("%s %d todos", action.verb, len(todos))
+	}
+
+	err := store.Batch(description, func(tx storage.Storage) error {
+		for _, t := range todos {
+			// Reload: an earlier item may have changed this one (e.g. --cascade)
+			todo, err := tx.GetByID(t.ID)
+			if err != nil {
+				return err
+			}
+
+			out, err := fn(tx, todo)
+			if err != nil {
+				return fmt.Errorf("failed to %s todo #%d: %w", action.verb, todo.ID, err)
+			}
+
+			switch {
+			case out.skipped:
+				skipped++
+				lines = append(lines, fmt.Sprintf("Skipped todo #%d: %s (%s)", todo.ID, todo.Title, out.reason))
+			case bulkDryRun:
+				changed++
+				lines = append(lines, fmt.Sprintf("Would %s todo #%d: %s", action.verb, todo.ID, todo.Title))
+			default:
+				changed++
+				lines = append(lines, fmt.Sprintf("%s todo #%d: %s", action.past, todo.ID, todo.Title))
+			}
+			if out.extra != "" && !out.skipped {
+				lines = append(lines, out.extra)
+			}
+		}
+
+		if bulkDryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		if len(todos) > 1 {
+			return fmt.Errorf("%w (no todos were changed)", err)
+		}
+		return err
+	}
+
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	summary := fmt.Sprintf("%s %d todo(s)", action.past, changed)
+	if bulkDryRun {
+		summary = fmt.Sprintf("Dry run: would %s %d todo(s)", action.verb, changed)
+	}
+	if skipped > 0 {
+		summary += fmt.Sprintf(", skipped %d", skipped)
+	}
+	if bulkDryRun {
+		fmt.Printf("\n%s; nothing was changed\n", summary)
+	} else if len(todos) > 1 {
+		fmt.Printf("\n%s\n", summary)
+	}
+	return nil
+}
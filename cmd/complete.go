@@ -1,48 +1,65 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
-	"time"
 
 	"github.com/spf13/cobra"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/storage"
 )
 
+var completeCascade bool
+
 var completeCmd = &cobra.Command{
-	Use:   "complete <id>",
-	Short: "Mark a todo as complete",
-	Long: `Mark a todo as complete.
+	Use:   "complete <ids>...",
+	Short: "Mark todos as complete",
+	Long: `Mark todos as complete. Completing a recurring todo creates its next
+occurrence with the due date moved forward. A todo with open subtasks is
+only completed with --cascade, which completes the subtasks as well.
+
+Todos are selected by ID, by lists and ranges of IDs, or by filter. All of
+them are completed in one transaction: if one fails, none are changed.
 
 Examples:
   todo complete 1
-  todo complete 42`,
+  todo complete 1,4,7-12
+  todo complete 42 --cascade
+  todo complete --filter-tag #sprint-12
+  todo complete -q 'project:website and due < today' --dry-run`,
 	Aliases: []string{"done"},
-	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id, err := strconv.ParseInt(args[0], 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid ID: %s", args[0])
-		}
-
-		todo, err := store.GetByID(id)
+		pending := false
+		todos, err := selectBulk(args, &pending)
 		if err != nil {
 			return err
 		}
 
-		if todo.Completed {
-			fmt.Printf("Todo #%d is already completed.\n", id)
-			return nil
-		}
-
-		now := time.Now().UTC()
-		todo.Completed = true
-		todo.CompletedAt = &now
+		action := bulkAction{verb: "complete", past: "Completed"}
+		return runBulk(action, todos, func(tx storage.Storage, todo *model.Todo) (bulkOutcome, error) {
+			if todo.Completed {
+				return bulkOutcome{skipped: true, reason: "already completed"}, nil
+			}
 
-		if err := store.Update(todo); err != nil {
-			return fmt.Errorf("failed to complete todo: %w", err)
-		}
+			next, err := storage.Complete(tx, todo, completeCascade)
+			if errors.Is(err, storage.ErrOpenSubtasks) {
+				return bulkOutcome{}, fmt.Errorf("%w (use --cascade to complete them too)", err)
+			}
+			if err != nil {
+				return bulkOutcome{}, err
+			}
 
-		fmt.Printf("Completed todo #%d: %s\n", todo.ID, todo.Title)
-		return nil
+			var out bulkOutcome
+			if next != nil {
+				out.extra = fmt.Sprintf("Next occurrence #%d due %s", next.ID, next.FormatDue(dateLayout()))
+			}
+			return out, nil
+		})
 	},
 }
+
+func init() {
+	completeCmd.Flags().BoolVar(&completeCascade, "cascade", false, "Also complete open subtasks")
+	addBulkFlags(completeCmd)
+}
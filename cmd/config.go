@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/adrg/xdg"
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/config"
+	"todo_cli/internal/storage"
+)
+
+var (
+	dbFlag  string
+	profile string
+
+	// settings are the config file values in effect for --profile
+	settings config.Settings
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and change settings",
+	Long: `Show and change the settings in the config file. Settings apply to every
+command; with --profile they go into a named profile that overrides them
+when the same --profile is given.
+
+Settings:
+  db           database file (also --db and $TODO_DB, which take precedence)
+  workspace    workspace used when no db is set (see 'todo workspace')
+  list.sort    default sort of todo list, e.g. due,priority (see 'todo list --help')
+  list.order   default sort order of todo list: asc, desc
+  add.tags     tags added to every new todo, e.g. "#inbox"
+  date.format  how due dates are printed, as a Go layout, e.g. 02.01.2006
+
+Examples:
+  todo config list
+  todo config set list.sort due
+  todo config set --profile work db ~/work/todos.db
+  todo config set --profile work add.tags "#work"
+  todo --profile work list
+  todo config get date.format
+  todo config set date.format ""   # Unset`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a setting",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := config.Load(config.Path())
+		if err != nil {
+			return err
+		}
+		current, err := file.Profile(profile)
+		if err != nil {
+			return configError(err)
+		}
+
+		value, err := current.Get(args[0])
+		if err != nil {
+			return configError(err)
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Change a setting",
+	Long: `Change a setting, in the profile given with --profile or at the top level
+otherwise. An empty value unsets it.
+
+Examples:
+  todo config set list.sort priority
+  todo config set --profile work db ~/work/todos.db`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.Path()
+		file, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+
+		if err := file.Section(profile).Set(args[0], args[1]); err != nil {
+			return configError(err)
+		}
+		if err := file.Save(path); err != nil {
+			return err
+		}
+
+		where := ""
+		if profile != "" {
+			where = fmt.Sprintf(" in profile %s", profile)
+		}
+		if args[1] == "" {
+			fmt.Printf("Unset %s%s\n", args[0], where)
+		} else {
+			fmt.Printf("Set %s = %s%s\n", args[0], args[1], where)
+		}
+		return nil
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List all settings",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.Path()
+		file, err := config.Load(path)
+		if err != nil {
+			return err
+		}
+		current, err := file.Profile(profile)
+		if err != nil {
+			return configError(err)
+		}
+
+		fmt.Printf("Config file: %s\n", path)
+		if profile != "" {
+			fmt.Printf("Profile:     %s\n", profile)
+		}
+		fmt.Println()
+		for _, key := range config.Keys {
+			value, _ := current.Get(key)
+			if value == "" {
+				value = "(not set)"
+			}
+			fmt.Printf("%-12s %s\n", key, value)
+		}
+
+		if len(file.Profiles) > 0 {
+			names := make([]string, 0, len(file.Profiles))
+			for name := range file.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Printf("\nProfiles: %s\n", strings.Join(names, ", "))
+		}
+		return nil
+	},
+}
+
+// configError marks mistakes in setting or profile names and values as
+// invalid input
+func configError(err error) error {
+	return storage.Errorf(storage.ErrInvalid, "%w", err)
+}
+
+// loadSettings reads the settings in effect for --profile
+func loadSettings() error {
+	file, err := config.Load(config.Path())
+	if err != nil {
+		return err
+	}
+	settings, err = file.Profile(profile)
+	if err != nil {
+		return configError(err)
+	}
+	return nil
+}
+
+// dbPath returns the database to open: --db, then $TODO_DB, then the db
+// setting, then the database of the active workspace
+func dbPath() (string, error) {
+	if path := dbOverride(); path != "" {
+		return expandHome(path), nil
+	}
+	return storage.WorkspacePath(activeWorkspace())
+}
+
+// dbOverride returns the database file given with --db, $TODO_DB or the db
+// setting, which take precedence over workspaces
+func dbOverride() string {
+	for _, path := range []string{dbFlag, os.Getenv("TODO_DB"), settings.DB} {
+		if path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path == "~" {
+		return xdg.Home
+	}
+	if rest, ok := strings.CutPrefix(path, "~"+string(filepath.Separator)); ok {
+		return filepath.Join(xdg.Home, rest)
+	}
+	return path
+}
+
+func init() {
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configListCmd)
+}
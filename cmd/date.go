@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var dateCmd = &cobra.Command{
+	Use:   "date",
+	Short: "Date helpers",
+}
+
+var dateParseCmd = &cobra.Command{
+	Use:   "parse <expr>",
+	Short: "Show how a date is understood",
+	Long: `Show the due date an expression stands for, as todo add --due, todo edit
+--due, todo list --due and the TUI would read it.
+
+` + storage.DateGrammar + `
+
+Examples:
+  todo date parse next friday
+  todo date parse "in 3 days 9am"
+  todo date parse eom
+  todo --tz America/New_York date parse tomorrow 17:00`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		due, hasTime, err := storage.ParseDue(strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Date:  %s\n", due.Format("Mon 2006-01-02"))
+		if hasTime {
+			fmt.Printf("Time:  %s\n", due.Format("15:04"))
+		} else {
+			fmt.Println("Time:  none, due by the end of the day")
+		}
+		fmt.Printf("Zone:  %s (%s)\n", due.Location(), due.Format("MST, UTC-07:00"))
+		fmt.Printf("UTC:   %s\n", due.UTC().Format("2006-01-02 15:04:05"))
+		return nil
+	},
+}
+
+func init() {
+	dateCmd.AddCommand(dateParseCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var (
+	dbMigrateStatus bool
+	dbMigrateDryRun bool
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database maintenance",
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply pending schema migrations",
+	Long: `Apply pending schema migrations. Migrations also run automatically
+whenever the database is opened; this command lets you inspect them first.
+
+Examples:
+  todo db migrate --status    # Show applied and pending migrations
+  todo db migrate --dry-run   # Show what would be applied
+  todo db migrate             # Apply pending migrations`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := dbPath()
+		if err != nil {
+			return fmt.Errorf("failed to get database path: %w", err)
+		}
+
+		migrator, err := storage.NewMigrator(path)
+		if err != nil {
+			return err
+		}
+		defer migrator.Close()
+
+		if dbMigrateStatus {
+			statuses, err := migrator.Status()
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Database: %s\n\n", path)
+			fmt.Printf("%-8s %-8s %-17s %s\n", "Version", "Status", "Applied", "Description")
+			fmt.Println(strings.Repeat("-", 70))
+			for _, st := range statuses {
+				status, applied := "pending", ""
+				if st.Applied() {
+					status = "applied"
+					applied = st.AppliedAt.Local().Format("2006-01-02 15:04")
+				}
+				fmt.Printf("%-8d %-8s %-17s %s\n", st.Version, status, applied, st.Description)
+			}
+			return nil
+		}
+
+		if dbMigrateDryRun {
+			pending, err := migrator.Pending()
+			if err != nil {
+				return err
+			}
+			if len(pending) == 0 {
+				fmt.Println("Database is up to date.")
+				return nil
+			}
+			fmt.Println("Would apply:")
+			for _, st := range pending {
+				fmt.Printf("  %d  %s\n", st.Version, st.Description)
+			}
+			return nil
+		}
+
+		applied, err := migrator.Migrate()
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date.")
+			return nil
+		}
+		for _, st := range applied {
+			fmt.Printf("Applied %d  %s\n", st.Version, st.Description)
+		}
+		return nil
+	},
+}
+
+func init() {
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateStatus, "status", false, "Show applied and pending migrations")
+	dbMigrateCmd.Flags().BoolVar(&dbMigrateDryRun, "dry-run", false, "Show pending migrations without applying them")
+
+	dbCmd.AddCommand(dbMigrateCmd)
+}
@@ -4,37 +4,68 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/storage"
 )
 
-var deleteYes bool
+var (
+	deleteYes      bool
+	deleteChildren string
+)
 
 var deleteCmd = &cobra.Command{
-	Use:   "delete <id>",
-	Short: "Delete a todo",
-	Long: `Delete a todo by ID. Requires confirmation unless --yes is specified.
+	Use:   "delete <ids>...",
+	Short: "Move todos to the trash",
+	Long: `Move todos to the trash. Requires confirmation unless --yes is specified.
+Trashed todos can be restored with 'todo trash restore' until they are
+purged. Todos are selected by ID, by lists and ranges of IDs, or by filter,
+and deleted in one transaction.
+
+Subtasks of the deleted todo are handled by --children:
+  promote  move them up to the deleted todo's parent (default)
+  cascade  move them to the trash too
+  refuse   don't delete a todo that has subtasks
 
 Examples:
   todo delete 1
-  todo delete 1 --yes`,
+  todo delete 1 --yes
+  todo delete 1 --children cascade
+  todo delete 3,5,9-11
+  todo delete -q 'status:done and completed < -90d' --dry-run`,
 	Aliases: []string{"rm", "remove"},
-	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id, err := strconv.ParseInt(args[0], 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid ID: %s", args[0])
+		policy := storage.OrphanPolicy(strings.ToLower(deleteChildren))
+		switch policy {
+		case storage.OrphanPromote, storage.OrphanCascade, storage.OrphanRefuse:
+		default:
+			return storage.Errorf(storage.ErrInvalid, "invalid --children policy: %s (use promote, cascade, or refuse)", deleteChildren)
 		}
 
-		todo, err := store.GetByID(id)
+		todos, err := selectBulk(args, nil)
 		if err != nil {
 			return err
 		}
 
-		if !deleteYes {
-			fmt.Printf("Delete todo #%d: %s? [y/N] ", todo.ID, todo.Title)
+		if !deleteYes && !bulkDryRun && len(todos) > 0 {
+			var prompt string
+			if len(todos) == 1 {
+				todo := todos[0]
+				prompt = fmt.Sprintf("Delete todo #%d: %s?", todo.ID, todo.Title)
+				if todo.HasSubtasks() {
+					prompt = fmt.Sprintf("Delete todo #%d: %s (%d subtask(s) will %s)?",
+						todo.ID, todo.Title, todo.SubtaskTotal, orphanPolicyVerb(policy))
+				}
+			} else {
+				for _, todo := range todos {
+					fmt.Printf("  #%d %s\n", todo.ID, todo.Title)
+				}
+				prompt = fmt.Sprintf("Delete these %d todos?", len(todos))
+			}
+			fmt.Printf("%s [y/N] ", prompt)
 			reader := bufio.NewReader(os.Stdin)
 			response, err := reader.ReadString('\n')
 			if err != nil {
@@ -48,15 +79,29 @@ Examples:
 			}
 		}
 
-		if err := store.Delete(id); err != nil {
-			return fmt.Errorf("failed to delete todo: %w", err)
-		}
-
-		fmt.Printf("Deleted todo #%d: %s\n", todo.ID, todo.Title)
-		return nil
+		action := bulkAction{verb: "delete", past: "Trashed"}
+		return runBulk(action, todos, func(tx storage.Storage, todo *model.Todo) (bulkOutcome, error) {
+			if todo.IsDeleted() {
+				return bulkOutcome{skipped: true, reason: "already in the trash"}, nil
+			}
+			return bulkOutcome{}, tx.DeleteWithPolicy(todo.ID, policy)
+		})
 	},
 }
 
+func orphanPolicyVerb(policy storage.OrphanPolicy) string {
+	switch policy {
+	case storage.OrphanCascade:
+		return "also be deleted"
+	case storage.OrphanRefuse:
+		return "block deletion"
+	default:
+		return "move up a level"
+	}
+}
+
 func init() {
 	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Skip confirmation")
+	deleteCmd.Flags().StringVar(&deleteChildren, "children", string(storage.OrphanPromote), "What to do with subtasks: promote, cascade, refuse")
+	addBulkFlags(deleteCmd)
 }
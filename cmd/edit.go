@@ -2,10 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"strconv"
 
 	"github.com/spf13/cobra"
 
+	"todo_cli/internal/model"
 	"todo_cli/internal/storage"
 )
 
@@ -17,12 +17,21 @@ var (
 	editDescription string
 	editClearDue    bool
 	editClearTags   bool
+	editRepeat      string
+	editClearRepeat bool
+	editParent      int64
+	editClearParent bool
+	editProject     string
+	editClearProj   bool
 )
 
 var editCmd = &cobra.Command{
-	Use:   "edit <id>",
-	Short: "Edit a todo",
-	Long: `Edit an existing todo's title, tags, due date, priority, or description.
+	Use:   "edit <ids>...",
+	Short: "Edit todos",
+	Long: `Edit an existing todo's title, tags, due date, priority, description, or repeat rule.
+
+Several todos can be edited at once by lists and ranges of IDs or by filter;
+they are all updated in one transaction.
 
 Examples:
   todo edit 1 --title "New title"
@@ -30,83 +39,140 @@ Examples:
   todo edit 1 --due 2026-02-20
   todo edit 1 --priority 2
   todo edit 1 --clear-due
-  todo edit 1 --clear-tags`,
-	Args: cobra.ExactArgs(1),
+  todo edit 1 --clear-tags
+  todo edit 1 --repeat "monthly on the last friday"
+  todo edit 1 --clear-repeat
+  todo edit 3 --parent 1
+  todo edit 3 --clear-parent
+  todo edit 3 --project website
+  todo edit 4-9 --priority 2
+  todo edit --filter-tag #sprint-12 --due next-week --dry-run`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id, err := strconv.ParseInt(args[0], 10, 64)
+		edit, err := editFunc(cmd)
 		if err != nil {
-			return fmt.Errorf("invalid ID: %s", args[0])
+			return err
+		}
+		if edit == nil {
+			fmt.Println("No changes specified. Use --title, --tags, --due, --priority, --desc, --repeat, --parent, --project, or a --clear-* flag.")
+			return nil
 		}
 
-		todo, err := store.GetByID(id)
+		todos, err := selectBulk(args, nil)
 		if err != nil {
 			return err
 		}
 
-		modified := false
+		action := bulkAction{verb: "update", past: "Updated"}
+		return runBulk(action, todos, func(tx storage.Storage, todo *model.Todo) (bulkOutcome, error) {
+			edit(todo)
+			return bulkOutcome{}, tx.Update(todo)
+		})
+	},
+}
 
-		if editTitle != "" {
-			todo.Title = editTitle
-			modified = true
-		}
+// editFunc turns the edit flags into a function applying them to a todo. It
+// returns nil when no change was asked for.
+func editFunc(cmd *cobra.Command) (func(todo *model.Todo), error) {
+	var edits []func(todo *model.Todo)
 
-		if editDescription != "" {
-			todo.Description = editDescription
-			modified = true
-		}
+	if editTitle != "" {
+		edits = append(edits, func(todo *model.Todo) { todo.Title = editTitle })
+	}
 
-		if editTags != "" {
-			todo.Tags = storage.ParseTags(editTags)
-			modified = true
-		}
+	if editDescription != "" {
+		edits = append(edits, func(todo *model.Todo) { todo.Description = editDescription })
+	}
 
-		if editClearTags {
-			todo.Tags = nil
-			modified = true
-		}
+	if editTags != "" {
+		tags := storage.ParseTags(editTags)
+		edits = append(edits, func(todo *model.Todo) { todo.Tags = tags })
+	}
 
-		if editDue != "" {
-			dueDate, err := storage.ParseDueDate(editDue)
-			if err != nil {
-				return fmt.Errorf("invalid due date: %w", err)
-			}
-			todo.DueDate = dueDate
-			modified = true
+	if editClearTags {
+		edits = append(edits, func(todo *model.Todo) { todo.Tags = nil })
+	}
+
+	if editDue != "" {
+		dueDate, hasTime, err := storage.ParseDue(editDue)
+		if err != nil {
+			return nil, storage.Errorf(storage.ErrInvalid, "invalid due date: %w", err)
 		}
+		edits = append(edits, func(todo *model.Todo) {
+			todo.DueDate = dueDate
+			todo.DueHasTime = hasTime
+		})
+	}
 
-		if editClearDue {
+	if editClearDue {
+		edits = append(edits, func(todo *model.Todo) {
 			todo.DueDate = nil
-			modified = true
-		}
+			todo.DueHasTime = false
+		})
+	}
 
-		if cmd.Flags().Changed("priority") {
-			if editPriority < 0 || editPriority > 5 {
-				return fmt.Errorf("priority must be between 0 and 5 (1=highest, 5=lowest, 0=none)")
-			}
-			todo.Priority = editPriority
-			modified = true
+	if cmd.Flags().Changed("priority") {
+		if editPriority < 0 || editPriority > 5 {
+			return nil, storage.Errorf(storage.ErrInvalid, "priority must be between 0 and 5 (1=highest, 5=lowest, 0=none)")
 		}
+		edits = append(edits, func(todo *model.Todo) { todo.Priority = editPriority })
+	}
 
-		if !modified {
-			fmt.Println("No changes specified. Use --title, --tags, --due, --priority, --desc, --clear-due, or --clear-tags.")
-			return nil
+	if editRepeat != "" {
+		rule, err := model.ParseRecurrence(editRepeat)
+		if err != nil {
+			return nil, storage.Errorf(storage.ErrInvalid, "invalid repeat rule: %w", err)
 		}
+		edits = append(edits, func(todo *model.Todo) { todo.Recurrence = rule.String() })
+	}
+
+	if editClearRepeat {
+		edits = append(edits, func(todo *model.Todo) { todo.Recurrence = "" })
+	}
+
+	if cmd.Flags().Changed("parent") {
+		edits = append(edits, func(todo *model.Todo) { todo.ParentID = &editParent })
+	}
 
-		if err := store.Update(todo); err != nil {
-			return fmt.Errorf("failed to update todo: %w", err)
+	if editClearParent {
+		edits = append(edits, func(todo *model.Todo) { todo.ParentID = nil })
+	}
+
+	if editProject != "" {
+		projectID, err := resolveProject(editProject)
+		if err != nil {
+			return nil, err
 		}
+		edits = append(edits, func(todo *model.Todo) { todo.ProjectID = projectID })
+	}
 
-		fmt.Printf("Updated todo #%d: %s\n", todo.ID, todo.Title)
-		return nil
-	},
+	if editClearProj {
+		edits = append(edits, func(todo *model.Todo) { todo.ProjectID = nil })
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+
+	return func(todo *model.Todo) {
+		for _, edit := range edits {
+			edit(todo)
+		}
+	}, nil
 }
 
 func init() {
 	editCmd.Flags().StringVar(&editTitle, "title", "", "New title")
 	editCmd.Flags().StringVarP(&editTags, "tags", "t", "", "New tags (replaces existing)")
-	editCmd.Flags().StringVarP(&editDue, "due", "d", "", "New due date")
+	editCmd.Flags().StringVarP(&editDue, "due", "d", "", "New due date, optionally with a time (e.g., 'tomorrow 17:00', 'eom'; see 'todo date parse --help')")
 	editCmd.Flags().IntVarP(&editPriority, "priority", "p", 0, "New priority (1-5, 0=none)")
 	editCmd.Flags().StringVar(&editDescription, "desc", "", "New description")
 	editCmd.Flags().BoolVar(&editClearDue, "clear-due", false, "Clear the due date")
 	editCmd.Flags().BoolVar(&editClearTags, "clear-tags", false, "Clear all tags")
+	editCmd.Flags().StringVarP(&editRepeat, "repeat", "r", "", "New repeat rule (e.g., 'every weekday')")
+	editCmd.Flags().BoolVar(&editClearRepeat, "clear-repeat", false, "Stop repeating")
+	editCmd.Flags().StringVar(&editProject, "project", "", "Move to a project")
+	editCmd.Flags().BoolVar(&editClearProj, "clear-project", false, "Remove from its project")
+	editCmd.Flags().Int64Var(&editParent, "parent", 0, "Move under another todo")
+	editCmd.Flags().BoolVar(&editClearParent, "clear-parent", false, "Make this a top-level todo")
+	addBulkFlags(editCmd)
 }
@@ -1,19 +1,24 @@
 package cmd
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
+	"iter"
 	"os"
 
 	"github.com/spf13/cobra"
 
+	"todo_cli/internal/model"
 	"todo_cli/internal/storage"
 )
 
 var exportCmd = &cobra.Command{
 	Use:   "export <filename>",
 	Short: "Export todos to JSON",
-	Long: `Export all todos to a JSON file.
+	Long: `Export all todos to a JSON file. Todos are written as they are read,
+so exports of large databases run in constant memory.
 
 Examples:
   todo export todos.json
@@ -22,22 +27,53 @@ Examples:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		filename := args[0]
 
-		// Get all todos (no filter)
-		todos, err := store.List(storage.Filter{})
+		file, err := os.Create(filename)
 		if err != nil {
-			return fmt.Errorf("failed to list todos: %w", err)
+			return fmt.Errorf("failed to write file: %w", err)
 		}
-
-		data, err := json.MarshalIndent(todos, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal todos: %w", err)
+		// Archived todos are included
+		n, err := exportTodos(file, store.Stream(storage.Filter{IncludeArchived: true}))
+		if cerr := file.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to write file: %w", cerr)
 		}
-
-		if err := os.WriteFile(filename, data, 0644); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
+		if err != nil {
+			os.Remove(filename)
+			return err
 		}
 
-		fmt.Printf("Exported %d todo(s) to %s\n", len(todos), filename)
+		fmt.Printf("Exported %d todo(s) to %s\n", n, filename)
 		return nil
 	},
 }
+
+// exportTodos writes todos to w as an indented JSON array, one at a time
+func exportTodos(w io.Writer, todos iter.Seq2[*model.Todo, error]) (int, error) {
+	buf := bufio.NewWriter(w)
+	buf.WriteString("[")
+
+	n := 0
+	for todo, err := range todos {
+		if err != nil {
+			return n, fmt.Errorf("failed to list todos: %w", err)
+		}
+		data, err := json.MarshalIndent(todo, "  ", "  ")
+		if err != nil {
+			return n, fmt.Errorf("failed to marshal todos: %w", err)
+		}
+		if n > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n  ")
+		buf.Write(data)
+		n++
+	}
+
+	if n > 0 {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("]\n")
+	if err := buf.Flush(); err != nil {
+		return n, fmt.Errorf("failed to write file: %w", err)
+	}
+	return n, nil
+}
@@ -2,18 +2,22 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"todo_cli/internal/model"
+	"todo_cli/internal/storage"
 )
 
 var importCmd = &cobra.Command{
 	Use:   "import <filename>",
 	Short: "Import todos from JSON",
 	Long: `Import todos from a JSON file. Each todo will be created as a new entry.
+Subtask relationships within the file are preserved. The import happens in
+one transaction: if any todo fails to import, nothing is imported.
 
 Examples:
   todo import todos.json
@@ -33,26 +37,92 @@ Examples:
 		}
 
 		imported := 0
-		for _, todo := range todos {
-			// Create as new todo (ID will be assigned by storage)
-			newTodo := &model.Todo{
-				Title:       todo.Title,
-				Description: todo.Description,
-				Tags:        todo.Tags,
-				DueDate:     todo.DueDate,
-				Completed:   todo.Completed,
-				CompletedAt: todo.CompletedAt,
-				Priority:    todo.Priority,
-			}
-
-			if err := store.Create(newTodo); err != nil {
-				fmt.Printf("Warning: failed to import todo '%s': %v\n", todo.Title, err)
-				continue
-			}
-			imported++
+		err = store.Batch("import "+filename, func(tx storage.Storage) error {
+			var err error
+			imported, err = importTodos(tx, todos)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%w (nothing was imported)", err)
 		}
 
 		fmt.Printf("Imported %d todo(s) from %s\n", imported, filename)
 		return nil
 	},
 }
+
+// importTodos creates the todos of an export file and returns how many were
+// imported
+func importTodos(tx storage.Storage, todos []model.Todo) (int, error) {
+	imported := 0
+	newIDs := make(map[int64]int64, len(todos))
+	var created []*model.Todo
+	for _, todo := range todos {
+		// Create as new todo (ID will be assigned by storage)
+		newTodo := &model.Todo{
+			Title:       todo.Title,
+			Description: todo.Description,
+			Tags:        todo.Tags,
+			DueDate:     todo.DueDate,
+			DueHasTime:  todo.DueHasTime && todo.DueDate != nil,
+			Completed:   todo.Completed,
+			CompletedAt: todo.CompletedAt,
+			Priority:    todo.Priority,
+			Recurrence:  todo.Recurrence,
+			ArchivedAt:  todo.ArchivedAt,
+		}
+
+		if todo.Project != "" {
+			projectID, err := importedProjectID(tx, todo.Project)
+			if err != nil {
+				return 0, fmt.Errorf("failed to set project of '%s': %w", todo.Title, err)
+			}
+			newTodo.ProjectID = projectID
+		}
+
+		if err := tx.Create(newTodo); err != nil {
+			return 0, fmt.Errorf("failed to import todo '%s': %w", todo.Title, err)
+		}
+		imported++
+
+		if todo.ID != 0 {
+			newIDs[todo.ID] = newTodo.ID
+		}
+		// Parents may appear later in the file, so link them afterwards
+		newTodo.ParentID = todo.ParentID
+		created = append(created, newTodo)
+	}
+
+	for _, todo := range created {
+		if todo.ParentID == nil {
+			continue
+		}
+		parentID, ok := newIDs[*todo.ParentID]
+		if !ok {
+			fmt.Printf("Warning: parent #%d of '%s' is not in the file\n", *todo.ParentID, todo.Title)
+			continue
+		}
+		todo.ParentID = &parentID
+		if err := tx.Update(todo); err != nil {
+			return 0, fmt.Errorf("failed to link subtask '%s': %w", todo.Title, err)
+		}
+	}
+
+	return imported, nil
+}
+
+// importedProjectID returns the ID of the named project, creating it if needed
+func importedProjectID(tx storage.Storage, name string) (*int64, error) {
+	project, err := tx.GetProject(name)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
+		project = &model.Project{Name: name}
+		if err := tx.CreateProject(project); err != nil {
+			return nil, err
+		}
+	}
+
+	return &project.ID, nil
+}
@@ -1,114 +1,160 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"todo_cli/internal/model"
+	"todo_cli/internal/query"
 	"todo_cli/internal/storage"
 )
 
 var (
-	listFilterTag string
-	listDue       string
-	listOverdue   bool
-	listCompleted bool
-	listPending   bool
-	listAll       bool
-	listSort      string
-	listSortOrder string
+	listFilterTag  string
+	listTagAny     string
+	listTagAll     string
+	listTagNone    string
+	listDue        string
+	listOverdue    bool
+	listCompleted  bool
+	listPending    bool
+	listAll        bool
+	listArchived   bool
+	listSort       string
+	listSortOrder  string
+	listProject    string
+	listBlocked    bool
+	listReady      bool
+	listQuery      string
+	listWorkspaces string
+
+	listDueFrom          string
+	listDueTo            string
+	listNoDue            bool
+	listCreatedSince     string
+	listUpdatedSince     string
+	listCompletedSince   string
+	listCompletedBetween string
+
+	listPriority    string
+	listPriorityMin int
+	listPriorityMax int
+
+	listLimit int
+	listPage  int
+	listAfter int64
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [@view]",
 	Short: "List todos",
 	Long: `List todos with optional filters.
 
+Pass @name to list a saved view (see 'todo view'); other flags refine it.
+
 Examples:
   todo list                    # List pending todos
+  todo list @today             # List a saved view
   todo list --all              # List all todos
   todo list --completed        # List completed todos
+  todo list --all --include-archived  # Everything, archived todos too
   todo list --filter-tag #work # Filter by tag
+  todo list --tag-any "#work #oncall" --tag-none "#someday"
+  todo list --project website  # Filter by project
   todo list --due today        # Due today
   todo list --due tomorrow     # Due tomorrow
   todo list --due next-week    # Due within 7 days
+  todo list --due friday       # Due on a day (see 'todo date parse --help')
+  todo list --due-from today --due-to eom   # Due this month, from today
+  todo list --no-due           # No due date
   todo list --overdue          # Past due date
-  todo list --sort priority    # Sort by priority`,
+  todo list --created-since 7d # Added in the last 7 days
+  todo list --completed-between 2026-09-01..2026-09-30
+  todo list --blocked          # Waiting on other todos
+  todo list --ready            # Nothing left blocking them
+  todo list --priority 1-2     # Urgent and high priority
+  todo list --priority-max 3   # Priority 1 to 3
+  todo list --sort priority    # Sort by priority
+  todo list --sort due,priority,-created
+  todo list --sort due:nulls-first  # Todos without a due date first
+  todo list -q 'priority <= 2 and (tag:#work or tag:#oncall) and due < +3d'
+  todo list --limit 20 --page 2  # Todos 21 to 40
+  todo list --limit 20 --after 118  # The 20 todos listed after #118
+  todo list --workspaces all   # Todos of every workspace
+  todo list --workspaces work,home
+
+Query language (-q):
+  Conditions are field op value, with op one of = != < <= > >= or :
+  and combined with and, or, not and parentheses. Bare words and
+  "quoted phrases" are full-text searched.
+
+  status     open, done, blocked, ready, overdue
+  priority   1-5 or urgent, high, medium, low, lowest; none
+  due, created, updated, completed
+             2026-03-01, today, friday, eom, 2026-W14, +3d, -2w; none
+             (see 'todo date parse --help')
+  tag, project, title, desc, id
+
+  A query on status or completed includes completed todos unless
+  --pending or --completed is given.
+
+Sorting (--sort):
+  Keys are priority, due, created, updated and title, separated by
+  commas. Each sorts in its natural order (highest priority, soonest due
+  date, newest, A-Z) unless prefixed with + (ascending) or - (descending).
+  Todos without a due date or priority sort last either way; add
+  :nulls-first to the key to list them first. Ties keep creation order.`,
 	Aliases: []string{"ls"},
+	Args:    cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		filter := storage.Filter{
-			SortOrder: storage.SortDesc,
-		}
-
-		// Completed filter
-		if listCompleted {
-			completed := true
-			filter.Completed = &completed
-		} else if listPending || !listAll {
-			completed := false
-			filter.Completed = &completed
-		}
-
-		// Tag filter
-		if listFilterTag != "" {
-			filter.Tags = storage.ParseTags(listFilterTag)
-		}
-
-		// Due date filter
-		if listOverdue {
-			filter.DueDate = &storage.DueDateFilter{Type: storage.DueOverdue}
-		} else if listDue != "" {
-			switch strings.ToLower(listDue) {
-			case "today":
-				filter.DueDate = &storage.DueDateFilter{Type: storage.DueToday}
-			case "tomorrow":
-				filter.DueDate = &storage.DueDateFilter{Type: storage.DueTomorrow}
-			case "next-week", "nextweek":
-				filter.DueDate = &storage.DueDateFilter{Type: storage.DueNextWeek}
-			default:
-				dueDate, err := storage.ParseDueDate(listDue)
-				if err != nil {
-					return fmt.Errorf("invalid due date filter: %w", err)
-				}
-				filter.DueDate = &storage.DueDateFilter{
-					Type:         storage.DueSpecific,
-					SpecificDate: dueDate,
-				}
+		var base *storage.Filter
+		if len(args) == 1 {
+			name, ok := strings.CutPrefix(args[0], "@")
+			if !ok {
+				return storage.Errorf(storage.ErrInvalid, "unexpected argument %q (saved views are listed with @name)", args[0])
+			}
+			view, err := store.GetView(name)
+			if err != nil {
+				return err
+			}
+			base = &view.Filter
+		}
+
+		// Saved views keep their own sort; the config only sets the default
+		if base == nil && listSort == "" {
+			listSort = settings.List.Sort
+			if listSortOrder == "" {
+				listSortOrder = settings.List.Order
 			}
 		}
 
-		// Sort
-		switch strings.ToLower(listSort) {
-		case "priority", "p":
-			filter.SortBy = storage.SortByPriority
-			filter.SortOrder = storage.SortAsc // 1 (highest) first
-		case "due", "d":
-			filter.SortBy = storage.SortByDueDate
-			filter.SortOrder = storage.SortAsc
-		case "created", "c":
-			filter.SortBy = storage.SortByCreated
-		case "updated", "u":
-			filter.SortBy = storage.SortByUpdated
-		case "title", "t":
-			filter.SortBy = storage.SortByTitle
-			filter.SortOrder = storage.SortAsc
-		}
-
-		// Override sort order if specified
-		if listSortOrder != "" {
-			switch strings.ToLower(listSortOrder) {
-			case "asc", "a":
-				filter.SortOrder = storage.SortAsc
-			case "desc", "d":
-				filter.SortOrder = storage.SortDesc
+		filter, err := listFilter(base)
+		if err != nil {
+			return err
+		}
+
+		if listWorkspaces != "" {
+			if listLimit != 0 || listPage != 0 || listAfter != 0 {
+				return storage.Errorf(storage.ErrInvalid, "--limit, --page and --after can't be combined with --workspaces")
 			}
+			return listAcrossWorkspaces(cmd.Context(), filter)
+		}
+		if err := paginate(&filter); err != nil {
+			return err
 		}
 
 		todos, err := store.List(filter)
 		if err != nil {
+			var qerr *query.Error
+			if errors.As(err, &qerr) {
+				return queryError(err)
+			}
 			return fmt.Errorf("failed to list todos: %w", err)
 		}
 
@@ -117,20 +163,339 @@ Examples:
 			return nil
 		}
 
+		more := listLimit > 0 && len(todos) > listLimit
+		if more {
+			todos = todos[:listLimit]
+		}
 		printTodoList(todos)
+		if more {
+			page := max(listPage, 1) + 1
+			fmt.Printf("More todos follow: use --page %d, or --after %d\n", page, todos[len(todos)-1].ID)
+		}
 		return nil
 	},
 }
 
+// paginate applies --limit, --page and --after to filter. It asks for one
+// todo more than a page holds to tell whether another page follows.
+func paginate(filter *storage.Filter) error {
+	if listLimit < 0 || listPage < 0 {
+		return storage.Errorf(storage.ErrInvalid, "--limit and --page must be positive")
+	}
+	if listPage > 0 && listLimit == 0 {
+		return storage.Errorf(storage.ErrInvalid, "--page needs --limit")
+	}
+	if listPage > 0 && listAfter != 0 {
+		return storage.Errorf(storage.ErrInvalid, "--page and --after are mutually exclusive")
+	}
+
+	if listAfter != 0 {
+		// The todo's sort values are where the page starts
+		if _, err := store.GetByID(listAfter); err != nil {
+			return err
+		}
+		filter.AfterID = listAfter
+	}
+	if listLimit > 0 {
+		filter.Limit = listLimit + 1
+	}
+	if listPage > 1 {
+		filter.Offset = (listPage - 1) * listLimit
+	}
+	return nil
+}
+
+// listAcrossWorkspaces lists the todos matching filter in each workspace
+// named by --workspaces, grouped by workspace
+func listAcrossWorkspaces(ctx context.Context, filter storage.Filter) error {
+	names, err := selectWorkspaces(listWorkspaces)
+	if err != nil {
+		return err
+	}
+
+	var groups []workspaceTodos
+	total := 0
+	for _, name := range names {
+		path, err := storage.WorkspacePath(name)
+		if err != nil {
+			return err
+		}
+		s, err := storage.NewSQLiteStorageWithPath(path)
+		if err != nil {
+			return fmt.Errorf("failed to open workspace %s: %w", name, err)
+		}
+		todos, err := s.WithContext(ctx).List(filter)
+		s.Close()
+		if err != nil {
+			return fmt.Errorf("failed to list todos in workspace %s: %w", name, queryError(err))
+		}
+
+		groups = append(groups, workspaceTodos{name: name, todos: todos})
+		total += len(todos)
+	}
+
+	if total == 0 {
+		fmt.Println("No todos found.")
+		return nil
+	}
+
+	printTodoTable(groups)
+	return nil
+}
+
+// listFilter builds a filter from the list flags. Starting from a saved
+// view's filter, only the flags that were given change it.
+func listFilter(base *storage.Filter) (storage.Filter, error) {
+	filter := storage.Filter{
+		SortOrder: storage.SortDesc,
+	}
+	pendingByDefault := true
+	if base != nil {
+		filter = *base
+		pendingByDefault = false
+	}
+
+	// Query language filter
+	queryOwnsStatus := false
+	if listQuery != "" {
+		expr, err := query.Parse(listQuery)
+		if err != nil {
+			return filter, queryError(err)
+		}
+		if filter.Query != "" {
+			filter.Query = "(" + filter.Query + ") and (" + listQuery + ")"
+		} else {
+			filter.Query = listQuery
+		}
+		queryOwnsStatus = query.Uses(expr, query.FieldStatus) || query.Uses(expr, query.FieldCompleted)
+	}
+
+	// Completed filter. Asking for a completion time includes completed
+	// todos, like a query on status or completed does.
+	completedRange := listCompletedSince != "" || listCompletedBetween != ""
+	if listCompleted {
+		completed := true
+		filter.Completed = &completed
+	} else if listPending || (pendingByDefault && !listAll && !queryOwnsStatus && !completedRange) {
+		completed := false
+		filter.Completed = &completed
+	} else if listAll {
+		filter.Completed = nil
+	}
+
+	if listArchived {
+		filter.IncludeArchived = true
+	}
+
+	// Tag filters
+	filter.Tags = append(filter.Tags, append(storage.ParseTags(listFilterTag), storage.ParseTags(listTagAll)...)...)
+	filter.TagsAny = append(filter.TagsAny, storage.ParseTags(listTagAny)...)
+	filter.TagsNone = append(filter.TagsNone, storage.ParseTags(listTagNone)...)
+
+	// Project filter. Across workspaces the project only needs to exist
+	// in some of them.
+	if listProject != "" {
+		if listWorkspaces == "" {
+			if _, err := store.GetProject(listProject); err != nil {
+				return filter, err
+			}
+		}
+		filter.Project = listProject
+	}
+
+	// Dependency filter
+	if listBlocked && listReady {
+		return filter, storage.Errorf(storage.ErrInvalid, "--blocked and --ready are mutually exclusive")
+	}
+	if listBlocked || listReady {
+		blocked := listBlocked
+		filter.Blocked = &blocked
+	}
+
+	// Due date filter
+	if listOverdue {
+		filter.DueDate = &storage.DueDateFilter{Type: storage.DueOverdue}
+	} else if listDue != "" {
+		switch strings.ToLower(listDue) {
+		case "today":
+			filter.DueDate = &storage.DueDateFilter{Type: storage.DueToday}
+		case "tomorrow":
+			filter.DueDate = &storage.DueDateFilter{Type: storage.DueTomorrow}
+		case "next-week", "nextweek":
+			filter.DueDate = &storage.DueDateFilter{Type: storage.DueNextWeek}
+		default:
+			dueDate, err := storage.ParseDueDate(listDue)
+			if err != nil {
+				return filter, storage.Errorf(storage.ErrInvalid, "invalid due date filter: %w", err)
+			}
+			filter.DueDate = &storage.DueDateFilter{
+				Type:         storage.DueSpecific,
+				SpecificDate: dueDate,
+			}
+		}
+	}
+
+	if listNoDue {
+		if listOverdue || listDue != "" || listDueFrom != "" || listDueTo != "" {
+			return filter, storage.Errorf(storage.ErrInvalid, "--no-due can't be combined with other due date filters")
+		}
+		filter.NoDue = true
+	}
+
+	// Timestamp ranges
+	if listDueFrom != "" || listDueTo != "" {
+		for _, end := range []string{listDueFrom, listDueTo} {
+			if _, ok := storage.ParseAge(end); ok {
+				return filter, storage.Errorf(storage.ErrInvalid,
+					"invalid --due-from/--due-to %q: ages count back from now, use a date like +7d or -7d", end)
+			}
+		}
+		r, err := timeRange("--due-from/--due-to", &storage.TimeRange{From: listDueFrom, To: listDueTo})
+		if err != nil {
+			return filter, err
+		}
+		filter.DueRange = r
+	}
+	if listCreatedSince != "" {
+		r, err := timeRange("--created-since", &storage.TimeRange{From: listCreatedSince})
+		if err != nil {
+			return filter, err
+		}
+		filter.CreatedRange = r
+	}
+	if listUpdatedSince != "" {
+		r, err := timeRange("--updated-since", &storage.TimeRange{From: listUpdatedSince})
+		if err != nil {
+			return filter, err
+		}
+		filter.UpdatedRange = r
+	}
+	if listCompletedSince != "" && listCompletedBetween != "" {
+		return filter, storage.Errorf(storage.ErrInvalid, "--completed-since and --completed-between are mutually exclusive")
+	}
+	if listCompletedSince != "" {
+		r, err := timeRange("--completed-since", &storage.TimeRange{From: listCompletedSince})
+		if err != nil {
+			return filter, err
+		}
+		filter.CompletedRange = r
+	}
+	if listCompletedBetween != "" {
+		r, err := storage.ParseTimeRange(listCompletedBetween)
+		if err != nil {
+			return filter, storage.Errorf(storage.ErrInvalid, "invalid --completed-between: %w", err)
+		}
+		filter.CompletedRange = r
+	}
+
+	// Priority filter
+	if listPriority != "" {
+		if listPriorityMin != 0 || listPriorityMax != 0 {
+			return filter, storage.Errorf(storage.ErrInvalid, "--priority can't be combined with --priority-min or --priority-max")
+		}
+		low, high, err := parsePriorityRange(listPriority)
+		if err != nil {
+			return filter, err
+		}
+		filter.PriorityMin, filter.PriorityMax = low, high
+	}
+	for _, p := range []int{listPriorityMin, listPriorityMax} {
+		if p < 0 || p > 5 {
+			return filter, storage.Errorf(storage.ErrInvalid, "priority must be between 1 and 5 (1=highest, 5=lowest)")
+		}
+	}
+	if listPriorityMin != 0 {
+		filter.PriorityMin = listPriorityMin
+	}
+	if listPriorityMax != 0 {
+		filter.PriorityMax = listPriorityMax
+	}
+	if filter.PriorityMin != 0 && filter.PriorityMax != 0 && filter.PriorityMin > filter.PriorityMax {
+		return filter, storage.Errorf(storage.ErrInvalid, "empty priority range %d-%d", filter.PriorityMin, filter.PriorityMax)
+	}
+
+	// Sort. --order applies to the sort keys without + or -.
+	var order storage.SortOrder
+	switch strings.ToLower(listSortOrder) {
+	case "asc", "a":
+		order = storage.SortAsc
+	case "desc", "d":
+		order = storage.SortDesc
+	case "":
+	default:
+		return filter, storage.Errorf(storage.ErrInvalid, "invalid --order %q (use asc or desc)", listSortOrder)
+	}
+	if listSort != "" {
+		keys, err := storage.ParseSort(listSort, order)
+		if err != nil {
+			return filter, err
+		}
+		filter.Sort = keys
+		filter.SortBy, filter.SortOrder = "", ""
+	} else if order != "" {
+		if len(filter.Sort) > 0 {
+			filter.Sort[0].Order = order
+		} else {
+			filter.SortOrder = order
+		}
+	}
+
+	return filter, nil
+}
+
+// queryError points at the offending column of an invalid -q expression
+func queryError(err error) error {
+	var qerr *query.Error
+	if !errors.As(err, &qerr) {
+		return err
+	}
+	return storage.Errorf(storage.ErrInvalid, "invalid query at %s\n\n  %s", qerr.Error(), strings.ReplaceAll(qerr.Caret(), "\n", "\n  "))
+}
+
+// workspaceTodos are the todos listed from one workspace
+type workspaceTodos struct {
+	name  string // empty for the current database
+	todos []model.Todo
+}
+
 func printTodoList(todos []model.Todo) {
+	printTodoTable([]workspaceTodos{{todos: todos}})
+}
+
+// printTodoTable prints todos as a table, with a workspace column when they
+// come from named workspaces
+func printTodoTable(groups []workspaceTodos) {
+	showWorkspace := groups[0].name != ""
+
 	// Print header
-	fmt.Printf("%-4s %-1s %-40s %-12s %-10s %s\n", "ID", "P", "Title", "Due", "Tags", "Status")
-	fmt.Println(strings.Repeat("-", 85))
+	if showWorkspace {
+		fmt.Printf("%-12s ", "Workspace")
+	}
+	fmt.Printf("%-4s %-1s %-40s %-17s %-10s %s\n", "ID", "P", "Title", "Due", "Tags", "Status")
+	if showWorkspace {
+		fmt.Print(strings.Repeat("-", 13))
+	}
+	fmt.Println(strings.Repeat("-", 90))
+
+	total := 0
+	for _, group := range groups {
+		printTodoRows(group)
+		total += len(group.todos)
+	}
+
+	fmt.Printf("\nTotal: %d todo(s)\n", total)
+}
 
-	for _, todo := range todos {
+// printTodoRows prints the table rows of one workspace, subtasks below
+// their parents
+func printTodoRows(group workspaceTodos) {
+	for _, item := range model.BuildTree(group.todos, nil) {
+		todo := item.Todo
 		status := "[ ]"
 		if todo.Completed {
 			status = "[x]"
+		} else if todo.IsBlocked() {
+			status = "[ ] blocked"
 		}
 
 		priority := " "
@@ -139,6 +504,12 @@ func printTodoList(todos []model.Todo) {
 		}
 
 		title := todo.Title
+		if todo.HasSubtasks() {
+			title += fmt.Sprintf(" (%d/%d)", todo.SubtaskDone, todo.SubtaskTotal)
+		}
+		if item.Depth > 0 {
+			title = strings.Repeat("  ", item.Depth-1) + "└ " + title
+		}
 		if len(title) > 40 {
 			title = title[:37] + "..."
 		}
@@ -146,13 +517,13 @@ func printTodoList(todos []model.Todo) {
 		dueStr := ""
 		if todo.DueDate != nil {
 			if todo.IsOverdue() && !todo.Completed {
-				dueStr = todo.DueDate.Format("2006-01-02") + "!"
+				dueStr = todo.FormatDue(dateLayout()) + "!"
 			} else if todo.IsDueToday() {
-				dueStr = "today"
+				dueStr = "today" + dueTimeSuffix(todo)
 			} else if todo.IsDueTomorrow() {
-				dueStr = "tomorrow"
+				dueStr = "tomorrow" + dueTimeSuffix(todo)
 			} else {
-				dueStr = todo.DueDate.Format("2006-01-02")
+				dueStr = todo.FormatDue(dateLayout())
 			}
 		}
 
@@ -164,27 +535,121 @@ func printTodoList(todos []model.Todo) {
 			}
 		}
 
-		fmt.Printf("%-4d %-1s %-40s %-12s %-10s %s\n",
+		if group.name != "" {
+			workspace := group.name
+			if len(workspace) > 12 {
+				workspace = workspace[:9] + "..."
+			}
+			fmt.Printf("%-12s ", workspace)
+		}
+		fmt.Printf("%-4d %-1s %-40s %-17s %-10s %s\n",
 			todo.ID, priority, title, dueStr, tagsStr, status)
 	}
-
-	fmt.Printf("\nTotal: %d todo(s)\n", len(todos))
 }
 
 func formatDueDate(t *time.Time) string {
 	if t == nil {
 		return ""
 	}
-	return t.Format("2006-01-02")
+	return formatDate(*t)
+}
+
+// formatDate prints the local date of t in the date.format setting
+func formatDate(t time.Time) string {
+	return t.Local().Format(dateLayout())
+}
+
+// dateLayout returns the date.format setting, 2006-01-02 by default
+func dateLayout() string {
+	if settings.Date.Format != "" {
+		return settings.Date.Format
+	}
+	return "2006-01-02"
+}
+
+// dueTimeSuffix returns " 15:04" for a todo due at a time of day
+func dueTimeSuffix(todo model.Todo) string {
+	if !todo.DueHasTime {
+		return ""
+	}
+	return todo.DueDate.Local().Format(" 15:04")
+}
+
+// parsePriorityRange parses --priority: a level like 2 or high, or a range
+// like 1-2 whose ends may be left out
+func parsePriorityRange(s string) (low, high int, err error) {
+	level := func(v string) (int, error) {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if n, ok := query.PriorityNames[v]; ok {
+			return n, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 5 {
+			return 0, storage.Errorf(storage.ErrInvalid, "invalid priority %q in --priority %s (use 1-5 or urgent, high, medium, low, lowest)", v, s)
+		}
+		return n, nil
+	}
+
+	from, to, isRange := strings.Cut(s, "-")
+	if !isRange {
+		n, err := level(from)
+		return n, n, err
+	}
+	if strings.TrimSpace(from) != "" {
+		if low, err = level(from); err != nil {
+			return 0, 0, err
+		}
+	}
+	if strings.TrimSpace(to) != "" {
+		if high, err = level(to); err != nil {
+			return 0, 0, err
+		}
+	}
+	return low, high, nil
+}
+
+// timeRange checks that the ends of a range flag resolve
+func timeRange(flag string, r *storage.TimeRange) (*storage.TimeRange, error) {
+	if _, _, err := r.Bounds(); err != nil {
+		return nil, storage.Errorf(storage.ErrInvalid, "invalid %s: %w", flag, err)
+	}
+	return r, nil
 }
 
 func init() {
-	listCmd.Flags().StringVar(&listFilterTag, "filter-tag", "", "Filter by tag (e.g., '#work')")
-	listCmd.Flags().StringVar(&listDue, "due", "", "Filter by due date (today, tomorrow, next-week, or date)")
-	listCmd.Flags().BoolVar(&listOverdue, "overdue", false, "Show overdue todos")
-	listCmd.Flags().BoolVar(&listCompleted, "completed", false, "Show completed todos")
-	listCmd.Flags().BoolVar(&listPending, "pending", false, "Show pending todos (default)")
-	listCmd.Flags().BoolVar(&listAll, "all", false, "Show all todos")
-	listCmd.Flags().StringVar(&listSort, "sort", "", "Sort by: priority, due, created, updated, title")
-	listCmd.Flags().StringVar(&listSortOrder, "order", "", "Sort order: asc, desc")
+	addListFlags(listCmd)
+	listCmd.Flags().StringVar(&listWorkspaces, "workspaces", "", "List todos of these workspaces (comma-separated, or all)")
+	listCmd.Flags().IntVar(&listLimit, "limit", 0, "List at most this many todos")
+	listCmd.Flags().IntVar(&listPage, "page", 0, "List this page of --limit todos (starting at 1)")
+	listCmd.Flags().Int64Var(&listAfter, "after", 0, "List the todos that follow this todo ID in the sort order")
+}
+
+// addListFlags registers the filter and sort flags shared by list and view save
+func addListFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&listFilterTag, "filter-tag", "", "Filter by tag (e.g., '#work'); same as --tag-all")
+	cmd.Flags().StringVar(&listTagAll, "tag-all", "", "Only todos with all of these tags")
+	cmd.Flags().StringVar(&listTagAny, "tag-any", "", "Only todos with at least one of these tags")
+	cmd.Flags().StringVar(&listTagNone, "tag-none", "", "Only todos with none of these tags")
+	cmd.Flags().StringVar(&listProject, "project", "", "Filter by project")
+	cmd.Flags().StringVar(&listDue, "due", "", "Filter by due date (today, tomorrow, next-week, or a date like friday or eom)")
+	cmd.Flags().StringVar(&listDueFrom, "due-from", "", "Only todos due on or after this date (e.g. today, -7d, +7d)")
+	cmd.Flags().StringVar(&listDueTo, "due-to", "", "Only todos due on or before this date (e.g. eow, +7d)")
+	cmd.Flags().BoolVar(&listNoDue, "no-due", false, "Only todos without a due date")
+	cmd.Flags().BoolVar(&listOverdue, "overdue", false, "Show overdue todos")
+	cmd.Flags().StringVar(&listCreatedSince, "created-since", "", "Only todos created since an age (7d, 12h) or date")
+	cmd.Flags().StringVar(&listUpdatedSince, "updated-since", "", "Only todos changed since an age (7d, 12h) or date")
+	cmd.Flags().StringVar(&listCompletedSince, "completed-since", "", "Only todos completed since an age (7d, 12h) or date")
+	cmd.Flags().StringVar(&listCompletedBetween, "completed-between", "", "Only todos completed in a range of dates or ages, e.g. 2026-09-01..2026-09-30 or 14d..7d")
+	cmd.Flags().BoolVar(&listCompleted, "completed", false, "Show completed todos")
+	cmd.Flags().BoolVar(&listPending, "pending", false, "Show pending todos (default)")
+	cmd.Flags().BoolVar(&listBlocked, "blocked", false, "Show todos waiting on open blockers")
+	cmd.Flags().BoolVar(&listReady, "ready", false, "Show todos with no open blockers")
+	cmd.Flags().StringVarP(&listQuery, "query", "q", "", "Filter with a query expression (see 'todo list --help')")
+	cmd.Flags().BoolVar(&listAll, "all", false, "Show all todos")
+	cmd.Flags().BoolVar(&listArchived, "include-archived", false, "Also show archived todos (see 'todo archive')")
+	cmd.Flags().StringVar(&listPriority, "priority", "", "Only todos of a priority or range of priorities (e.g., 2, 1-2, 3-)")
+	cmd.Flags().IntVar(&listPriorityMin, "priority-min", 0, "Only todos with a priority of at least this number (1=highest)")
+	cmd.Flags().IntVar(&listPriorityMax, "priority-max", 0, "Only todos with a priority of at most this number (1=highest)")
+	cmd.Flags().StringVar(&listSort, "sort", "", "Sort by comma-separated keys, -key for descending: priority, due, created, updated, title")
+	cmd.Flags().StringVar(&listSortOrder, "order", "", "Sort order of keys without + or -: asc, desc")
 }
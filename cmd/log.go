@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var logSince string
+
+var logCmd = &cobra.Command{
+	Use:   "log [id]",
+	Short: "Show the change history",
+	Long: `Show who changed what and when. With an ID, show the full history of
+that todo; otherwise show changes to all todos, including deleted ones.
+
+Examples:
+  todo log 12
+  todo log --since 7d
+  todo log --since 2026-03-01`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var events []storage.Event
+		var err error
+
+		if len(args) == 1 {
+			id, perr := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+			if perr != nil {
+				return storage.Errorf(storage.ErrInvalid, "invalid ID: %s", args[0])
+			}
+			events, err = store.History(id)
+		} else {
+			since, perr := parseSince(logSince, time.Now())
+			if perr != nil {
+				return perr
+			}
+			events, err = store.Events(since)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			fmt.Println("No changes found.")
+			return nil
+		}
+
+		for _, e := range events {
+			fmt.Printf("%s  #%-4d %-8s %s", e.At.Local().Format("2006-01-02 15:04"), e.TodoID, e.Verb(), e.Change())
+			if e.Actor != "" {
+				fmt.Printf("  (%s)", e.Actor)
+			}
+			fmt.Println()
+		}
+		return nil
+	},
+}
+
+// parseSince turns an age (7d) or a date into the start of a time window
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if age, ok := storage.ParseAge(s); ok {
+		return age.Before(now), nil
+	}
+
+	d, err := storage.ParseDueDate(s)
+	if err != nil || d == nil {
+		return time.Time{}, storage.Errorf(storage.ErrInvalid, "invalid time %q (use an age like 7d or 12h, or a date)", s)
+	}
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location()), nil
+}
+
+func init() {
+	logCmd.Flags().StringVar(&logSince, "since", "7d", "Show changes since an age (7d, 12h) or date")
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/model"
+)
+
+var (
+	projectListAll        bool
+	projectArchiveRestore bool
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage projects",
+	Long: `Manage projects. A todo belongs to at most one project; use tags for
+cross-cutting labels.
+
+Examples:
+  todo project add website
+  todo project list
+  todo project rename website site
+  todo project archive site`,
+	Aliases: []string{"projects", "proj"},
+}
+
+var projectAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Create a project",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project := &model.Project{Name: args[0]}
+		if err := store.CreateProject(project); err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+
+		fmt.Printf("Created project %s\n", project.Name)
+		return nil
+	},
+}
+
+var projectListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List projects with todo counts",
+	Aliases: []string{"ls"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := store.ProjectStats()
+		if err != nil {
+			return err
+		}
+
+		var archived []model.Project
+		if projectListAll {
+			projects, err := store.ListProjects(true)
+			if err != nil {
+				return err
+			}
+			for _, p := range projects {
+				if p.Archived {
+					archived = append(archived, p)
+				}
+			}
+		}
+
+		if len(stats) == 0 && len(archived) == 0 {
+			fmt.Println("No projects found.")
+			return nil
+		}
+
+		fmt.Printf("%-24s %6s %8s %10s\n", "Project", "Open", "Overdue", "Completed")
+		fmt.Println(strings.Repeat("-", 51))
+		for _, st := range stats {
+			fmt.Printf("%-24s %6d %8d %10d\n", st.Project.Name, st.Open, st.Overdue, st.Completed)
+		}
+		for _, p := range archived {
+			fmt.Printf("%-24s %s\n", p.Name, "(archived)")
+		}
+
+		return nil
+	},
+}
+
+var projectRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a project",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := store.RenameProject(args[0], args[1]); err != nil {
+			return err
+		}
+
+		fmt.Printf("Renamed project %s to %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var projectArchiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Archive a project",
+	Long: `Archive a project. Its todos are kept, but the project is hidden from
+project lists and no new todos can be added to it.
+
+Examples:
+  todo project archive website
+  todo project archive website --restore`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := store.ArchiveProject(args[0], !projectArchiveRestore); err != nil {
+			return err
+		}
+
+		if projectArchiveRestore {
+			fmt.Printf("Restored project %s\n", args[0])
+		} else {
+			fmt.Printf("Archived project %s\n", args[0])
+		}
+		return nil
+	},
+}
+
+// resolveProject looks up an active project by name for assigning todos
+func resolveProject(name string) (*int64, error) {
+	project, err := store.GetProject(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w (create it with 'todo project add %s')", err, name)
+	}
+	if project.Archived {
+		return nil, fmt.Errorf("project %q is archived", project.Name)
+	}
+	return &project.ID, nil
+}
+
+func init() {
+	projectListCmd.Flags().BoolVar(&projectListAll, "all", false, "Include archived projects")
+	projectArchiveCmd.Flags().BoolVar(&projectArchiveRestore, "restore", false, "Unarchive the project")
+
+	projectCmd.AddCommand(projectAddCmd)
+	projectCmd.AddCommand(projectListCmd)
+	projectCmd.AddCommand(projectRenameCmd)
+	projectCmd.AddCommand(projectArchiveCmd)
+}
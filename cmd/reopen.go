@@ -1,46 +1,43 @@
 package cmd
 
 import (
-	"fmt"
-	"strconv"
-
 	"github.com/spf13/cobra"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/storage"
 )
 
 var reopenCmd = &cobra.Command{
-	Use:   "reopen <id>",
-	Short: "Reopen a completed todo",
-	Long: `Mark a completed todo as pending again.
+	Use:   "reopen <ids>...",
+	Short: "Reopen completed todos",
+	Long: `Mark completed todos as pending again. Todos are selected by ID, by lists
+and ranges of IDs, or by filter, and reopened in one transaction.
 
 Examples:
   todo reopen 1
-  todo reopen 42`,
-	Aliases: []string{"undo"},
-	Args:    cobra.ExactArgs(1),
+  todo reopen 42,43
+  todo reopen --filter-tag #sprint-12 --dry-run`,
+	Aliases: []string{"uncomplete"},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		id, err := strconv.ParseInt(args[0], 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid ID: %s", args[0])
-		}
-
-		todo, err := store.GetByID(id)
+		completed := true
+		todos, err := selectBulk(args, &completed)
 		if err != nil {
 			return err
 		}
 
-		if !todo.Completed {
-			fmt.Printf("Todo #%d is not completed.\n", id)
-			return nil
-		}
+		action := bulkAction{verb: "reopen", past: "Reopened"}
+		return runBulk(action, todos, func(tx storage.Storage, todo *model.Todo) (bulkOutcome, error) {
+			if !todo.Completed {
+				return bulkOutcome{skipped: true, reason: "not completed"}, nil
+			}
 
-		todo.Completed = false
-		todo.CompletedAt = nil
-
-		if err := store.Update(todo); err != nil {
-			return fmt.Errorf("failed to reopen todo: %w", err)
-		}
-
-		fmt.Printf("Reopened todo #%d: %s\n", todo.ID, todo.Title)
-		return nil
+			todo.Completed = false
+			todo.CompletedAt = nil
+			return bulkOutcome{}, tx.Update(todo)
+		})
 	},
 }
+
+func init() {
+	addBulkFlags(reopenCmd)
+}
@@ -1,52 +1,172 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"todo_cli/internal/config"
+	"todo_cli/internal/query"
 	"todo_cli/internal/storage"
 )
 
+// Exit codes, so scripts can tell why a command failed
+const (
+	exitFailure  = 1   // any other error
+	exitInvalid  = 2   // malformed arguments, flags or queries
+	exitNotFound = 3   // the todo, project or view doesn't exist
+	exitConflict = 4   // the change clashes with the current state
+	exitTimeout  = 124 // --timeout expired
+	exitCanceled = 130 // interrupted
+)
+
 var (
 	store   storage.Storage
-	rootCmd = &cobra.Command{
+	timeout time.Duration
+	tz      string
+	cancel  context.CancelFunc = func() {}
+	rootCmd                    = &cobra.Command{
 		Use:   "todo",
 		Short: "A command-line TODO application",
 		Long: `A command-line TODO application with both CLI and interactive TUI modes.
 
-Manage your tasks with tags, due dates, and priorities.
-Use 'todo tui' for an interactive terminal interface.`,
+Manage your tasks with projects, tags, due dates, and priorities.
+Use 'todo tui' for an interactive terminal interface.
+
+The database and defaults for other commands can be set in a config file
+(see 'todo config'), optionally per profile selected with --profile.
+
+Exit status:
+  0    success
+  1    failure
+  2    invalid arguments, flags or query
+  3    todo, project or view not found
+  4    conflict with the current state (e.g. the todo is in the trash)
+  124  --timeout expired
+  130  interrupted`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			// Skip storage initialization for completion commands
+			// Dates are parsed and shown in time.Local and stored in UTC
+			if tz != "" {
+				loc, err := time.LoadLocation(tz)
+				if err != nil {
+					return storage.Errorf(storage.ErrInvalid, "invalid --tz %q (use a zone like Europe/Berlin or UTC)", tz)
+				}
+				time.Local = loc
+			}
+
+			// Skip storage initialization for completion, config, workspace,
+			// date and db commands. The db commands manage the database itself and
+			// must not open (and migrate) it first; the config and workspace
+			// commands may name a profile that doesn't exist yet, to create it.
 			if cmd.Name() == "completion" || cmd.Parent() != nil && cmd.Parent().Name() == "completion" {
 				return nil
 			}
+			if within(cmd, configCmd) {
+				return nil
+			}
+			if err := loadSettings(); err != nil {
+				if within(cmd, workspaceCmd) && errors.Is(err, config.ErrUnknownProfile) {
+					return nil
+				}
+				return err
+			}
+			if within(cmd, dbCmd) || within(cmd, workspaceCmd) || within(cmd, dateCmd) {
+				return nil
+			}
 
-			var err error
-			store, err = storage.NewSQLiteStorage()
+			ctx := cmd.Context()
+			if timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				cmd.SetContext(ctx)
+			}
+
+			path, err := dbPath()
+			if err != nil {
+				return fmt.Errorf("failed to get database path: %w", err)
+			}
+			s, err := storage.NewSQLiteStorageWithPath(path)
 			if err != nil {
 				return fmt.Errorf("failed to initialize storage: %w", err)
 			}
-			return nil
-		},
-		PersistentPostRun: func(cmd *cobra.Command, args []string) {
-			if store != nil {
-				store.Close()
+			store = s.WithContext(ctx)
+
+			// A dry run changes nothing, not even in the background
+			if f := cmd.Flags().Lookup("dry-run"); f != nil && f.Value.String() == "true" {
+				return nil
 			}
+			return autoArchive()
 		},
 	}
 )
 
 // Execute runs the root command
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	// Release the database whether or not the command succeeded, before
+	// os.Exit skips the deferred calls
+	cleanup()
+	if err != nil {
+		stop()
+		os.Exit(exitCode(err))
+	}
+}
+
+// cleanup cancels the --timeout context and closes the database, if the
+// command opened them
+func cleanup() {
+	cancel()
+	if store != nil {
+		store.Close()
+		store = nil
+	}
+}
+
+// within reports whether cmd is parent or one of its subcommands
+func within(cmd, parent *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// exitCode maps an error to the exit status documented in the root help
+func exitCode(err error) int {
+	var qerr *query.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(err, context.Canceled):
+		return exitCanceled
+	case errors.Is(err, storage.ErrNotFound):
+		return exitNotFound
+	case errors.Is(err, storage.ErrConflict):
+		return exitConflict
+	case errors.Is(err, storage.ErrInvalid), errors.As(err, &qerr):
+		return exitInvalid
+	default:
+		return exitFailure
 	}
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&dbFlag, "db", "", "Database file (default: $TODO_DB, the db setting or the active workspace)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "Use the settings of this config profile")
+	rootCmd.PersistentFlags().StringVar(&tz, "tz", "", "Time zone for dates and times (default: $TZ or the system zone)")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Give up on the command after this long (e.g. 5s)")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return storage.Errorf(storage.ErrInvalid, "%w", err)
+	})
+
 	rootCmd.AddCommand(addCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(showCmd)
@@ -54,7 +174,22 @@ func init() {
 	rootCmd.AddCommand(reopenCmd)
 	rootCmd.AddCommand(editCmd)
 	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(archiveCmd)
 	rootCmd.AddCommand(tuiCmd)
 	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(projectCmd)
+	rootCmd.AddCommand(tagsCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(viewCmd)
+	rootCmd.AddCommand(logCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(redoCmd)
+	rootCmd.AddCommand(blockCmd)
+	rootCmd.AddCommand(unblockCmd)
+	rootCmd.AddCommand(dbCmd)
+	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(workspaceCmd)
+	rootCmd.AddCommand(dateCmd)
 }
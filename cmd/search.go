@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var searchLimit int
+
+var highlightStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Full-text search across todos",
+	Long: `Search titles, descriptions and tags, most relevant first.
+
+Words match in any order and in any form ("deploy" finds "deploying").
+Use "quotes" for an exact phrase, a trailing * for a prefix, OR for
+alternatives and NOT or a leading - to exclude a term.
+
+Examples:
+  todo search release notes
+  todo search '"code review"'
+  todo search 'deplo* -staging'
+  todo search 'bug OR regression'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results, err := store.Search(strings.Join(args, " "), searchLimit)
+		if err != nil {
+			return fmt.Errorf("failed to search: %w", err)
+		}
+
+		if len(results) == 0 {
+			fmt.Println("No matching todos.")
+			return nil
+		}
+
+		for _, r := range results {
+			status := "[ ]"
+			if r.Todo.Completed {
+				status = "[x]"
+			}
+			archived := ""
+			if r.Todo.IsArchived() {
+				archived = " (archived)"
+			}
+			// A title match highlights the title itself rather than repeating it
+			if stripHighlight(r.Snippet) == r.Todo.Title {
+				fmt.Printf("#%-4d %s %s%s\n", r.Todo.ID, status, highlight(r.Snippet), archived)
+				continue
+			}
+			fmt.Printf("#%-4d %s %s%s\n", r.Todo.ID, status, r.Todo.Title, archived)
+			if r.Snippet != "" {
+				fmt.Printf("      %s\n", highlight(r.Snippet))
+			}
+		}
+
+		fmt.Printf("\n%d result(s)\n", len(results))
+		return nil
+	},
+}
+
+func init() {
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results (0 for all)")
+}
+
+// highlight renders the matched terms of a search snippet
+func highlight(snippet string) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+
+	var b strings.Builder
+	for {
+		start := strings.Index(snippet, storage.HighlightStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(snippet[start:], storage.HighlightEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(snippet[:start])
+		b.WriteString(highlightStyle.Render(snippet[start+len(storage.HighlightStart) : end]))
+		snippet = snippet[end+len(storage.HighlightEnd):]
+	}
+	b.WriteString(snippet)
+
+	return stripHighlight(b.String())
+}
+
+func stripHighlight(s string) string {
+	return strings.NewReplacer(storage.HighlightStart, "", storage.HighlightEnd, "").Replace(s)
+}
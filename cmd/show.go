@@ -6,6 +6,9 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/storage"
 )
 
 var showCmd = &cobra.Command{
@@ -20,7 +23,7 @@ Examples:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		id, err := strconv.ParseInt(args[0], 10, 64)
 		if err != nil {
-			return fmt.Errorf("invalid ID: %s", args[0])
+			return storage.Errorf(storage.ErrInvalid, "invalid ID: %s", args[0])
 		}
 
 		todo, err := store.GetByID(id)
@@ -39,7 +42,9 @@ Examples:
 		}
 
 		status := "Pending"
-		if todo.Completed {
+		if todo.IsDeleted() {
+			status = "In trash"
+		} else if todo.Completed {
 			status = "Completed"
 		}
 		fmt.Printf("Status:      %s\n", status)
@@ -48,12 +53,16 @@ Examples:
 			fmt.Printf("Priority:    %d (%s)\n", todo.Priority, todo.PriorityString())
 		}
 
+		if todo.Project != "" {
+			fmt.Printf("Project:     %s\n", todo.Project)
+		}
+
 		if len(todo.Tags) > 0 {
 			fmt.Printf("Tags:        %s\n", strings.Join(todo.Tags, " "))
 		}
 
 		if todo.DueDate != nil {
-			dueStr := todo.DueDate.Local().Format("2006-01-02 15:04")
+			dueStr := todo.FormatDue(dateLayout())
 			if todo.IsOverdue() && !todo.Completed {
 				dueStr += " (OVERDUE)"
 			} else if todo.IsDueToday() {
@@ -64,6 +73,18 @@ Examples:
 			fmt.Printf("Due:         %s\n", dueStr)
 		}
 
+		if todo.ParentID != nil {
+			parentStr := fmt.Sprintf("#%d", *todo.ParentID)
+			if parent, err := store.GetByID(*todo.ParentID); err == nil {
+				parentStr += " " + parent.Title
+			}
+			fmt.Printf("Parent:      %s\n", parentStr)
+		}
+
+		if rule := todo.RecurrenceRule(); rule != nil {
+			fmt.Printf("Repeats:     %s (%s)\n", rule.Describe(), todo.Recurrence)
+		}
+
 		fmt.Printf("Created:     %s\n", todo.CreatedAt.Local().Format("2006-01-02 15:04"))
 		fmt.Printf("Updated:     %s\n", todo.UpdatedAt.Local().Format("2006-01-02 15:04"))
 
@@ -71,6 +92,50 @@ Examples:
 			fmt.Printf("Completed:   %s\n", todo.CompletedAt.Local().Format("2006-01-02 15:04"))
 		}
 
+		if todo.ArchivedAt != nil {
+			fmt.Printf("Archived:    %s\n", todo.ArchivedAt.Local().Format("2006-01-02 15:04"))
+		}
+
+		if todo.DeletedAt != nil {
+			fmt.Printf("Deleted:     %s\n", todo.DeletedAt.Local().Format("2006-01-02 15:04"))
+		}
+
+		blockers, err := store.Blockers(todo.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load blockers: %w", err)
+		}
+		printRelated("Blocked by", blockers)
+
+		dependents, err := store.Dependents(todo.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load dependents: %w", err)
+		}
+		printRelated("Blocks", dependents)
+
+		if todo.HasSubtasks() {
+			children, err := store.List(storage.Filter{ParentID: &todo.ID, SortBy: storage.SortByCreated, SortOrder: storage.SortAsc})
+			if err != nil {
+				return fmt.Errorf("failed to load subtasks: %w", err)
+			}
+
+			printRelated(fmt.Sprintf("Subtasks (%s)", todo.ProgressString()), children)
+		}
+
 		return nil
 	},
 }
+
+func printRelated(heading string, todos []model.Todo) {
+	if len(todos) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", heading)
+	for _, t := range todos {
+		status := "[ ]"
+		if t.Completed {
+			status = "[x]"
+		}
+		fmt.Printf("  %s #%d %s\n", status, t.ID, t.Title)
+	}
+}
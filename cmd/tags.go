@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var tagsCmd = &cobra.Command{
+	Use:   "tags",
+	Short: "List tags with usage counts",
+	Long: `List every tag in use and how many todos carry it, most used first.
+
+Examples:
+  todo tags`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tags, err := store.GetAllTags()
+		if err != nil {
+			return fmt.Errorf("failed to list tags: %w", err)
+		}
+
+		if len(tags) == 0 {
+			fmt.Println("No tags found.")
+			return nil
+		}
+
+		for _, tc := range tags {
+			fmt.Printf("%5d  %s\n", tc.Count, tc.Tag)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var (
+	trashPurgeOlderThan string
+	trashPurgeYes       bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage deleted todos",
+	Long: `Deleted todos are kept in the trash until they are purged.
+
+Examples:
+  todo trash list
+  todo trash restore 12
+  todo trash purge --older-than 30d`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List todos in the trash",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		todos, err := store.List(storage.Filter{
+			Trashed:   true,
+			SortBy:    storage.SortByDeleted,
+			SortOrder: storage.SortDesc,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list trash: %w", err)
+		}
+
+		if len(todos) == 0 {
+			fmt.Println("The trash is empty.")
+			return nil
+		}
+
+		fmt.Printf("%-4s %-50s %s\n", "ID", "Title", "Deleted")
+		fmt.Println(strings.Repeat("-", 72))
+		for _, todo := range todos {
+			title := todo.Title
+			if len(title) > 50 {
+				title = title[:47] + "..."
+			}
+			fmt.Printf("%-4d %-50s %s\n", todo.ID, title, todo.DeletedAt.Local().Format("2006-01-02 15:04"))
+		}
+
+		fmt.Printf("\nTotal: %d todo(s)\n", len(todos))
+		return nil
+	},
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Restore a todo from the trash",
+	Long: `Restore a todo from the trash. Subtasks that were deleted along with it
+are restored too.
+
+Examples:
+  todo trash restore 12`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return storage.Errorf(storage.ErrInvalid, "invalid ID: %s", args[0])
+		}
+
+		restored, err := store.Restore(id)
+		if err != nil {
+			return err
+		}
+
+		for _, todo := range restored {
+			fmt.Printf("Restored todo #%d: %s\n", todo.ID, todo.Title)
+		}
+		return nil
+	},
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete todos in the trash",
+	Long: `Permanently delete todos in the trash. Requires confirmation unless --yes
+is specified.
+
+Examples:
+  todo trash purge
+  todo trash purge --older-than 30d --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		before := time.Now()
+		what := "all todos in the trash"
+		if trashPurgeOlderThan != "" {
+			age, ok := storage.ParseAge(trashPurgeOlderThan)
+			if !ok {
+				return storage.Errorf(storage.ErrInvalid, "invalid --older-than %q (use an age like 30d or 2w)", trashPurgeOlderThan)
+			}
+			before = age.Before(before)
+			what = fmt.Sprintf("todos deleted more than %s ago", trashPurgeOlderThan)
+		}
+
+		if !trashPurgeYes {
+			fmt.Printf("Permanently delete %s? [y/N] ", what)
+			reader := bufio.NewReader(os.Stdin)
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read response: %w", err)
+			}
+
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Cancelled.")
+				return nil
+			}
+		}
+
+		n, err := store.PurgeTrash(before)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Purged %d todo(s) from the trash\n", n)
+		return nil
+	},
+}
+
+func init() {
+	trashPurgeCmd.Flags().StringVar(&trashPurgeOlderThan, "older-than", "", "Only purge todos deleted longer ago than this age (30d, 2w)")
+	trashPurgeCmd.Flags().BoolVarP(&trashPurgeYes, "yes", "y", false, "Skip confirmation")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashPurgeCmd)
+}
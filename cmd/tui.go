@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 
+	"todo_cli/internal/storage"
 	"todo_cli/internal/tui"
 )
 
@@ -18,17 +19,45 @@ Keyboard shortcuts:
   j/↓       Move down
   k/↑       Move up
   Space     Toggle complete
+  C         Complete with subtasks
+  h/l       Collapse/expand subtasks
   Enter     View details
   /         Search
   t         Filter by tag
+  [/]       Previous/next project
+  v/V       Next/previous saved view
   p         Set priority
   e         Edit todo
   n         New todo
-  x         Toggle select
-  D         Delete selected
+  N         New subtask of selected
+  x         Toggle select (Space, C, p and D act on all selected)
+  D         Move selected to the trash
+  T         Open the trash (r: restore)
+  u         Undo last change
+  Ctrl+R    Redo
+  w         Switch to the next workspace (for this session)
   q/Esc     Quit / Back`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		app := tui.NewApp(store)
+		if dbOverride() == "" {
+			names, err := storage.Workspaces()
+			if err != nil {
+				return err
+			}
+			app.SetWorkspaces(names, activeWorkspace(), func(name string) (storage.Storage, error) {
+				path, err := storage.WorkspacePath(name)
+				if err != nil {
+					return nil, err
+				}
+				s, err := storage.NewSQLiteStorageWithPath(path)
+				if err != nil {
+					return nil, err
+				}
+				return s.WithContext(cmd.Context()), nil
+			})
+		}
+		defer app.Close()
+
 		p := tea.NewProgram(app, tea.WithAltScreen())
 
 		if _, err := p.Run(); err != nil {
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var undoList bool
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [n]",
+	Short: "Undo the last changes",
+	Long: `Undo the last n changes to todos (default 1). A change is one command:
+deleting several todos at once or completing a todo with its subtasks is
+undone in one step. Undone changes can be reapplied with 'todo redo' until
+something else is changed.
+
+Examples:
+  todo undo
+  todo undo 3
+  todo undo --list`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if undoList {
+			return printOperations()
+		}
+
+		n, err := replayCount(args)
+		if err != nil {
+			return err
+		}
+
+		ops, err := store.Undo(n)
+		if err != nil {
+			return err
+		}
+		if len(ops) == 0 {
+			fmt.Println("Nothing to undo.")
+			return nil
+		}
+
+		for _, op := range ops {
+			fmt.Printf("Undid: %s\n", op.Description)
+		}
+		return nil
+	},
+}
+
+var redoCmd = &cobra.Command{
+	Use:   "redo [n]",
+	Short: "Redo the last undone changes",
+	Long: `Reapply the last n changes reverted with 'todo undo' (default 1).
+
+Examples:
+  todo redo
+  todo redo 2`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		n, err := replayCount(args)
+		if err != nil {
+			return err
+		}
+
+		ops, err := store.Redo(n)
+		if err != nil {
+			return err
+		}
+		if len(ops) == 0 {
+			fmt.Println("Nothing to redo.")
+			return nil
+		}
+
+		for _, op := range ops {
+			fmt.Printf("Redid: %s\n", op.Description)
+		}
+		return nil
+	},
+}
+
+func replayCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, storage.Errorf(storage.ErrInvalid, "invalid count: %s", args[0])
+	}
+	return n, nil
+}
+
+func printOperations() error {
+	ops, err := store.Operations(20)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		fmt.Println("No changes to undo.")
+		return nil
+	}
+
+	for _, op := range ops {
+		fmt.Printf("%s  %s%s\n", op.At.Local().Format("2006-01-02 15:04"), op.Description, undoneMarker(op))
+	}
+	return nil
+}
+
+func undoneMarker(op storage.Operation) string {
+	if op.Undone {
+		return "  (undone)"
+	}
+	return ""
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List recent changes instead of undoing")
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/storage"
+)
+
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Manage saved views",
+	Long: `Save list filters under a name and list them again with 'todo list @name'.
+
+Examples:
+  todo view save today --due today --sort priority
+  todo view save oncall -q 'tag:#oncall and priority <= 2'
+  todo list @today
+  todo view list
+  todo view rm today`,
+	Aliases: []string{"views"},
+}
+
+var viewSaveCmd = &cobra.Command{
+	Use:   "save <name> [list flags]",
+	Short: "Save list flags as a named view",
+	Long: `Save a combination of 'todo list' flags as a named view. Saving under an
+existing name replaces that view. Relative dates such as --due today,
+--created-since 7d or -q 'due < +3d' are resolved each time the view is
+listed.
+
+Examples:
+  todo view save work --filter-tag #work --sort priority --due next-week
+  todo view save blocked --blocked --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimPrefix(args[0], "@")
+
+		filter, err := listFilter(nil)
+		if err != nil {
+			return err
+		}
+
+		if err := store.SaveView(name, filter); err != nil {
+			return err
+		}
+
+		fmt.Printf("Saved view @%s: todo list %s\n", name, strings.Join(filterArgs(filter), " "))
+		return nil
+	},
+}
+
+var viewListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List saved views",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		views, err := store.ListViews()
+		if err != nil {
+			return err
+		}
+
+		if len(views) == 0 {
+			fmt.Println("No saved views. Create one with 'todo view save <name> [list flags]'.")
+			return nil
+		}
+
+		for _, v := range views {
+			fmt.Printf("@%-20s %s\n", v.Name, strings.Join(filterArgs(v.Filter), " "))
+		}
+		return nil
+	},
+}
+
+var viewRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Short:   "Delete a saved view",
+	Aliases: []string{"delete"},
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.TrimPrefix(args[0], "@")
+		if err := store.DeleteView(name); err != nil {
+			return err
+		}
+
+		fmt.Printf("Deleted view @%s\n", name)
+		return nil
+	},
+}
+
+// filterArgs renders a filter as the list flags that produce it
+func filterArgs(f storage.Filter) []string {
+	var args []string
+	add := func(flag, value string) {
+		if strings.ContainsAny(value, " '\"#") {
+			value = "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+		}
+		args = append(args, flag, value)
+	}
+
+	if f.Completed == nil {
+		args = append(args, "--all")
+	} else if *f.Completed {
+		args = append(args, "--completed")
+	}
+	if f.IncludeArchived {
+		args = append(args, "--include-archived")
+	}
+	if len(f.Tags) > 0 {
+		add("--tag-all", strings.Join(f.Tags, " "))
+	}
+	if len(f.TagsAny) > 0 {
+		add("--tag-any", strings.Join(f.TagsAny, " "))
+	}
+	if len(f.TagsNone) > 0 {
+		add("--tag-none", strings.Join(f.TagsNone, " "))
+	}
+	if f.Project != "" {
+		add("--project", f.Project)
+	}
+	if f.Blocked != nil {
+		if *f.Blocked {
+			args = append(args, "--blocked")
+		} else {
+			args = append(args, "--ready")
+		}
+	}
+	if f.DueDate != nil {
+		switch f.DueDate.Type {
+		case storage.DueOverdue:
+			args = append(args, "--overdue")
+		case storage.DueSpecific:
+			if f.DueDate.SpecificDate != nil {
+				add("--due", f.DueDate.SpecificDate.Format("2006-01-02"))
+			}
+		default:
+			add("--due", string(f.DueDate.Type))
+		}
+	}
+	if f.DueRange != nil {
+		if f.DueRange.From != "" {
+			add("--due-from", f.DueRange.From)
+		}
+		if f.DueRange.To != "" {
+			add("--due-to", f.DueRange.To)
+		}
+	}
+	if f.NoDue {
+		args = append(args, "--no-due")
+	}
+	if f.CreatedRange != nil {
+		add("--created-since", f.CreatedRange.From)
+	}
+	if f.UpdatedRange != nil {
+		add("--updated-since", f.UpdatedRange.From)
+	}
+	if f.CompletedRange != nil {
+		if f.CompletedRange.To == "" {
+			add("--completed-since", f.CompletedRange.From)
+		} else {
+			add("--completed-between", f.CompletedRange.String())
+		}
+	}
+	if f.Query != "" {
+		add("-q", f.Query)
+	}
+	if f.PriorityMin != 0 || f.PriorityMax != 0 {
+		switch {
+		case f.PriorityMin == f.PriorityMax:
+			add("--priority", strconv.Itoa(f.PriorityMin))
+		case f.PriorityMax == 0:
+			add("--priority", fmt.Sprintf("%d-", f.PriorityMin))
+		case f.PriorityMin == 0:
+			add("--priority", fmt.Sprintf("-%d", f.PriorityMax))
+		default:
+			add("--priority", fmt.Sprintf("%d-%d", f.PriorityMin, f.PriorityMax))
+		}
+	}
+	if len(f.Sort) > 0 {
+		keys := make([]string, len(f.Sort))
+		for i, key := range f.Sort {
+			keys[i] = key.String()
+		}
+		add("--sort", strings.Join(keys, ","))
+	} else if f.SortBy != "" {
+		add("--sort", string(f.SortBy))
+		add("--order", string(f.SortOrder))
+	}
+
+	return args
+}
+
+func init() {
+	addListFlags(viewSaveCmd)
+
+	viewCmd.AddCommand(viewSaveCmd)
+	viewCmd.AddCommand(viewListCmd)
+	viewCmd.AddCommand(viewRmCmd)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"todo_cli/internal/config"
+	"todo_cli/internal/storage"
+)
+
+var workspaceCreateUse bool
+
+var workspaceCmd = &cobra.Command{
+	Use:     "workspace",
+	Short:   "Manage workspaces",
+	Aliases: []string{"ws"},
+	Long: `Workspaces keep separate sets of todos, each in its own database. The
+workspace chosen with 'todo workspace use' is remembered in the config file
+(per profile with --profile) and used by every command, unless a database
+is given with --db, $TODO_DB or the db setting.
+
+Examples:
+  todo workspace create work --use
+  todo workspace use default
+  todo workspace list
+  todo list --workspaces all`,
+}
+
+var workspaceCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		exists, err := storage.WorkspaceExists(name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return storage.Errorf(storage.ErrConflict, "workspace %s already exists", name)
+		}
+
+		path, err := storage.WorkspacePath(name)
+		if err != nil {
+			return err
+		}
+		s, err := storage.NewSQLiteStorageWithPath(path)
+		if err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+		s.Close()
+
+		fmt.Printf("Created workspace %s\n", name)
+		if workspaceCreateUse {
+			return useWorkspace(name)
+		}
+		return nil
+	},
+}
+
+var workspaceUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch to a workspace",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return useWorkspace(args[0])
+	},
+}
+
+var workspaceListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List workspaces",
+	Aliases: []string{"ls"},
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := storage.Workspaces()
+		if err != nil {
+			return err
+		}
+
+		active := activeWorkspace()
+		for _, name := range names {
+			marker := " "
+			if name == active && dbOverride() == "" {
+				marker = "*"
+			}
+			path, err := storage.WorkspacePath(name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s %-16s %s\n", marker, name, path)
+		}
+
+		if path := dbOverride(); path != "" {
+			fmt.Printf("\nUsing %s instead (set with --db, $TODO_DB or the db setting)\n", path)
+		}
+		return nil
+	},
+}
+
+// useWorkspace remembers name as the active workspace
+func useWorkspace(name string) error {
+	exists, err := storage.WorkspaceExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return storage.Errorf(storage.ErrNotFound, "workspace %s not found (create it with 'todo workspace create %s')", name, name)
+	}
+
+	path := config.Path()
+	file, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	value := name
+	if name == storage.DefaultWorkspace {
+		value = ""
+	}
+	if err := file.Section(profile).Set("workspace", value); err != nil {
+		return configError(err)
+	}
+	if err := file.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Switched to workspace %s\n", name)
+	if override := dbOverride(); override != "" {
+		fmt.Printf("Note: %s is used instead while set with --db, $TODO_DB or the db setting\n", override)
+	}
+	return nil
+}
+
+// activeWorkspace returns the workspace selected with 'todo workspace use'
+func activeWorkspace() string {
+	if settings.Workspace != "" {
+		return settings.Workspace
+	}
+	return storage.DefaultWorkspace
+}
+
+// selectWorkspaces resolves --workspaces: "all" or a comma-separated list
+func selectWorkspaces(value string) ([]string, error) {
+	if value == "all" {
+		return storage.Workspaces()
+	}
+
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		exists, err := storage.WorkspaceExists(name)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, storage.Errorf(storage.ErrNotFound, "workspace %s not found", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func init() {
+	workspaceCreateCmd.Flags().BoolVar(&workspaceCreateUse, "use", false, "Switch to the new workspace")
+
+	workspaceCmd.AddCommand(workspaceCreateCmd)
+	workspaceCmd.AddCommand(workspaceUseCmd)
+	workspaceCmd.AddCommand(workspaceListCmd)
+}
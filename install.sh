@@ -3,7 +3,8 @@
 set -e
 
 echo "Building todo CLI..."
-CGO_ENABLED=1 go build -ldflags="-s -w" -o todo .
+# sqlite_fts5 enables ranked full-text search (todo search)
+CGO_ENABLED=1 go build -tags sqlite_fts5 -ldflags="-s -w" -o todo .
 
 echo "Installing to /usr/local/bin/ (requires sudo)..."
 sudo mv todo /usr/local/bin/
@@ -0,0 +1,201 @@
+// Package config reads and writes the configuration file, a TOML file with
+// default settings and named profiles that override them:
+//
+//	db = "~/todos.db"
+//
+//	[list]
+//	sort = "due"
+//
+//	[profiles.work]
+//	db = "~/work/todos.db"
+//
+//	[profiles.work.add]
+//	tags = "#work"
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/adrg/xdg"
+
+	"todo_cli/internal/storage"
+)
+
+// ErrUnknownProfile is returned for a profile the config file doesn't define
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// ErrUnknownKey is returned for a setting name that doesn't exist
+var ErrUnknownKey = errors.New("unknown setting")
+
+// Settings are the values the config file and each profile can set. Empty
+// values fall back to the built-in defaults.
+type Settings struct {
+	DB        string       `toml:"db,omitempty"`
+	Workspace string       `toml:"workspace,omitempty"` // set by todo workspace use
+	List      ListSettings `toml:"list,omitempty"`
+	Add       AddSettings  `toml:"add,omitempty"`
+	Date      DateSettings `toml:"date,omitempty"`
+}
+
+// ListSettings are the defaults of todo list
+type ListSettings struct {
+	Sort  string `toml:"sort,omitempty"`  // sort keys, e.g. "due,priority,-created"
+	Order string `toml:"order,omitempty"` // asc or desc
+}
+
+// AddSettings are the defaults of todo add
+type AddSettings struct {
+	Tags string `toml:"tags,omitempty"` // added to every new todo, e.g. "#inbox"
+}
+
+// DateSettings control how dates are printed
+type DateSettings struct {
+	Format string `toml:"format,omitempty"` // Go layout, e.g. "02.01.2006"
+}
+
+// Keys lists the names of all settings, in the order config list shows them
+var Keys = []string{"db", "workspace", "list.sort", "list.order", "add.tags", "date.format"}
+
+// File is the content of the config file
+type File struct {
+	Settings
+	Profiles map[string]*Settings `toml:"profiles,omitempty"`
+}
+
+// Path returns the location of the config file under the XDG config directory
+func Path() string {
+	return filepath.Join(xdg.ConfigHome, "todocli", "config.toml")
+}
+
+// Load reads the config file at path. A missing file is an empty config.
+func Load(path string) (*File, error) {
+	f := &File{}
+	if _, err := toml.DecodeFile(path, f); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// Save writes the config file to path, creating its directory if needed
+func (f *File) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	var b strings.Builder
+	if err := toml.NewEncoder(&b).Encode(f); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Profile returns the settings in effect for the named profile: the
+// profile's own values over the top-level ones. An empty name selects the
+// top-level settings alone.
+func (f *File) Profile(name string) (Settings, error) {
+	if name == "" {
+		return f.Settings, nil
+	}
+	profile := f.Profiles[name]
+	if profile == nil {
+		return Settings{}, fmt.Errorf("%w %q (define it with 'todo config set --profile %s <key> <value>')", ErrUnknownProfile, name, name)
+	}
+
+	merged := f.Settings
+	for _, key := range Keys {
+		if value, _ := profile.Get(key); value != "" {
+			*merged.field(key) = value
+		}
+	}
+	return merged, nil
+}
+
+// Section returns the settings stored directly in the named profile, or at
+// the top level for an empty name, creating the profile if needed
+func (f *File) Section(name string) *Settings {
+	if name == "" {
+		return &f.Settings
+	}
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]*Settings)
+	}
+	if f.Profiles[name] == nil {
+		f.Profiles[name] = &Settings{}
+	}
+	return f.Profiles[name]
+}
+
+// Get returns a setting by name, or "" if it is not set
+func (s Settings) Get(key string) (string, error) {
+	field := s.field(key)
+	if field == nil {
+		return "", fmt.Errorf("%w %q (known: %s)", ErrUnknownKey, key, strings.Join(Keys, ", "))
+	}
+	return *field, nil
+}
+
+// Set validates and changes a setting; an empty value unsets it
+func (s *Settings) Set(key, value string) error {
+	field := s.field(key)
+	if field == nil {
+		return fmt.Errorf("%w %q (known: %s)", ErrUnknownKey, key, strings.Join(Keys, ", "))
+	}
+	if value != "" {
+		if err := validate(key, value); err != nil {
+			return err
+		}
+	}
+	*field = value
+	return nil
+}
+
+func (s *Settings) field(key string) *string {
+	switch key {
+	case "db":
+		return &s.DB
+	case "workspace":
+		return &s.Workspace
+	case "list.sort":
+		return &s.List.Sort
+	case "list.order":
+		return &s.List.Order
+	case "add.tags":
+		return &s.Add.Tags
+	case "date.format":
+		return &s.Date.Format
+	}
+	return nil
+}
+
+func validate(key, value string) error {
+	switch key {
+	case "workspace":
+		return storage.CheckWorkspaceName(value)
+	case "list.sort":
+		if _, err := storage.ParseSort(value, ""); err != nil {
+			return fmt.Errorf("invalid list.sort: %w", err)
+		}
+	case "list.order":
+		if value != "asc" && value != "desc" {
+			return fmt.Errorf("invalid list.order %q (use asc or desc)", value)
+		}
+	case "date.format":
+		// A layout without any reference component prints itself verbatim
+		if time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC).Format(value) == value {
+			return fmt.Errorf("invalid date.format %q (use a Go layout such as 2006-01-02 or 02.01.2006)", value)
+		}
+	}
+	return nil
+}
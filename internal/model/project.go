@@ -0,0 +1,13 @@
+package model
+
+import (
+	"time"
+)
+
+// Project groups related todos, independently of tags
+type Project struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Archived  bool      `json:"archived"`
+	CreatedAt time.Time `json:"created_at"`
+}
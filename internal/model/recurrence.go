@@ -0,0 +1,603 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base period of a recurrence rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// WeekdayNum is a weekday with an optional ordinal, e.g. the last Friday (-1FR)
+type WeekdayNum struct {
+	Ordinal int // 0=every, 1..5=nth in month, -1=last
+	Weekday time.Weekday
+}
+
+// Recurrence is an RRULE-style schedule (a subset of RFC 5545)
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int // 1..31, or -1 for the last day of the month
+	ByMonth    []int // 1..12, for yearly rules
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var ordinalWords = map[string]int{
+	"first": 1, "1st": 1, "second": 2, "2nd": 2, "third": 3, "3rd": 3,
+	"fourth": 4, "4th": 4, "fifth": 5, "5th": 5, "last": -1,
+}
+
+// ParseRecurrence parses either an RRULE string ("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO")
+// or a phrase such as "every weekday", "every 2 weeks on monday" or
+// "monthly on the last friday"
+func ParseRecurrence(s string) (*Recurrence, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, fmt.Errorf("empty recurrence rule")
+	}
+
+	upper := strings.ToUpper(s)
+	if strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		return parseRRule(strings.TrimPrefix(upper, "RRULE:"))
+	}
+	return parsePhrase(s)
+}
+
+func parseRRule(s string) (*Recurrence, error) {
+	r := &Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part: %s", part)
+		}
+
+		switch key {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = Frequency(value)
+			default:
+				return nil, fmt.Errorf("unsupported frequency: %s", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid interval: %s", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				wd, err := parseWeekdayCode(code)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n == 0 || n < -1 || n > 31 {
+					return nil, fmt.Errorf("invalid month day: %s", v)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil || n < 1 || n > 12 {
+					return nil, fmt.Errorf("invalid month: %s", v)
+				}
+				r.ByMonth = append(r.ByMonth, n)
+			}
+		default:
+			return nil, fmt.Errorf("unsupported rule part: %s", key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("recurrence rule is missing FREQ")
+	}
+	return r, r.validate()
+}
+
+func parseWeekdayCode(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday: %s", code)
+	}
+
+	wd, ok := weekdayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday: %s", code)
+	}
+
+	ordinal := 0
+	if prefix := code[:len(code)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -1 || n > 5 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday ordinal: %s", code)
+		}
+		ordinal = n
+	}
+
+	return WeekdayNum{Ordinal: ordinal, Weekday: wd}, nil
+}
+
+func parsePhrase(s string) (*Recurrence, error) {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '\t'
+	})
+
+	r := &Recurrence{Interval: 1}
+	i := 0
+	next := func() string {
+		if i < len(words) {
+			return words[i]
+		}
+		return ""
+	}
+
+	switch next() {
+	case "daily":
+		r.Freq = Daily
+		i++
+	case "weekly":
+		r.Freq = Weekly
+		i++
+	case "monthly":
+		r.Freq = Monthly
+		i++
+	case "yearly", "annually":
+		r.Freq = Yearly
+		i++
+	case "every":
+		i++
+		if n, err := strconv.Atoi(next()); err == nil {
+			if n < 1 {
+				return nil, fmt.Errorf("invalid interval: %d", n)
+			}
+			r.Interval = n
+			i++
+		} else if next() == "other" {
+			r.Interval = 2
+			i++
+		}
+
+		switch unit := next(); unit {
+		case "day", "days":
+			r.Freq = Daily
+			i++
+		case "week", "weeks":
+			r.Freq = Weekly
+			i++
+		case "month", "months":
+			r.Freq = Monthly
+			i++
+		case "year", "years":
+			r.Freq = Yearly
+			i++
+		case "weekday", "weekdays":
+			r.Freq = Weekly
+			r.ByDay = weekdaysMonToFri()
+			i++
+		case "weekend", "weekends":
+			r.Freq = Weekly
+			r.ByDay = []WeekdayNum{{Weekday: time.Saturday}, {Weekday: time.Sunday}}
+			i++
+		default:
+			// "every monday and thursday"
+			days, n := parseWeekdayList(words[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("unrecognized recurrence: %q", s)
+			}
+			r.Freq = Weekly
+			r.ByDay = days
+			i += n
+		}
+	default:
+		return nil, fmt.Errorf("unrecognized recurrence: %q (try \"every weekday\" or \"FREQ=WEEKLY;BYDAY=MO\")", s)
+	}
+
+	if next() == "on" {
+		i++
+		if next() == "the" {
+			i++
+		}
+
+		switch r.Freq {
+		case Weekly, Daily:
+			days, n := parseWeekdayList(words[i:])
+			if n == 0 {
+				return nil, fmt.Errorf("expected weekday after \"on\" in %q", s)
+			}
+			r.Freq = Weekly
+			r.ByDay = days
+			i += n
+		case Monthly:
+			n, err := r.parseMonthlyOn(words[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, s)
+			}
+			i += n
+		default:
+			return nil, fmt.Errorf("\"on\" is not supported for yearly rules in %q", s)
+		}
+	}
+
+	if i != len(words) {
+		return nil, fmt.Errorf("unexpected %q in recurrence %q", words[i], s)
+	}
+	return r, r.validate()
+}
+
+// parseMonthlyOn handles "last friday", "2nd tuesday", "15th", "day 15" and "last day"
+func (r *Recurrence) parseMonthlyOn(words []string) (int, error) {
+	if len(words) == 0 {
+		return 0, fmt.Errorf("expected day after \"on\"")
+	}
+
+	if words[0] == "day" && len(words) > 1 {
+		n, err := strconv.Atoi(words[1])
+		if err != nil || n < 1 || n > 31 {
+			return 0, fmt.Errorf("invalid month day %q", words[1])
+		}
+		r.ByMonthDay = []int{n}
+		return 2, nil
+	}
+
+	if ord, ok := ordinalWords[words[0]]; ok && len(words) > 1 {
+		if words[1] == "day" && ord == -1 {
+			r.ByMonthDay = []int{-1}
+			return 2, nil
+		}
+		if wd, ok := weekdayNames[words[1]]; ok {
+			r.ByDay = []WeekdayNum{{Ordinal: ord, Weekday: wd}}
+			return 2, nil
+		}
+	}
+
+	digits := strings.TrimRight(words[0], "stndrh")
+	if n, err := strconv.Atoi(digits); err == nil && n >= 1 && n <= 31 {
+		r.ByMonthDay = []int{n}
+		return 1, nil
+	}
+
+	return 0, fmt.Errorf("unrecognized month day %q", words[0])
+}
+
+func parseWeekdayList(words []string) ([]WeekdayNum, int) {
+	var days []WeekdayNum
+	n := 0
+	for n < len(words) {
+		w := words[n]
+		if w == "and" && len(days) > 0 {
+			n++
+			continue
+		}
+		wd, ok := weekdayNames[strings.TrimSuffix(w, "s")]
+		if !ok {
+			wd, ok = weekdayNames[w]
+		}
+		if !ok {
+			break
+		}
+		days = append(days, WeekdayNum{Weekday: wd})
+		n++
+	}
+	// Don't swallow a trailing "and"
+	if n > 0 && words[n-1] == "and" {
+		n--
+	}
+	return days, n
+}
+
+func weekdaysMonToFri() []WeekdayNum {
+	return []WeekdayNum{
+		{Weekday: time.Monday}, {Weekday: time.Tuesday}, {Weekday: time.Wednesday},
+		{Weekday: time.Thursday}, {Weekday: time.Friday},
+	}
+}
+
+func (r *Recurrence) validate() error {
+	for _, d := range r.ByDay {
+		if d.Ordinal != 0 && r.Freq != Monthly {
+			return fmt.Errorf("weekday ordinals are only valid for monthly rules")
+		}
+	}
+	if len(r.ByDay) > 0 && r.Freq == Yearly {
+		return fmt.Errorf("weekdays are not supported in yearly rules")
+	}
+	if len(r.ByDay) > 0 && len(r.ByMonthDay) > 0 {
+		return fmt.Errorf("a rule can repeat on weekdays or on month days, not both")
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq != Monthly && r.Freq != Yearly {
+		return fmt.Errorf("month days are only valid for monthly and yearly rules")
+	}
+	if len(r.ByMonth) > 0 && r.Freq != Yearly {
+		return fmt.Errorf("months are only valid for yearly rules")
+	}
+	return nil
+}
+
+// PinDay fixes the day a monthly or yearly rule without one repeats on to
+// the day of due. Without it, an occurrence clamped to a shorter month
+// (the 31st to April 30th, February 29th to the 28th) would anchor the
+// next one, and the rule would stay on the earlier day from then on.
+func (r *Recurrence) PinDay(due time.Time) {
+	switch {
+	case r.Freq == Monthly && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0:
+		r.ByMonthDay = []int{due.Day()}
+	case r.Freq == Yearly && len(r.ByMonthDay) == 0:
+		if len(r.ByMonth) == 0 {
+			r.ByMonth = []int{int(due.Month())}
+		}
+		r.ByMonthDay = []int{due.Day()}
+	}
+}
+
+// String returns the canonical RRULE form of the rule
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			code := strings.ToUpper(d.Weekday.String()[:2])
+			if d.Ordinal != 0 {
+				code = strconv.Itoa(d.Ordinal) + code
+			}
+			codes[i] = code
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = strconv.Itoa(m)
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe returns a human-readable description like "every 2 weeks on Monday"
+func (r *Recurrence) Describe() string {
+	units := map[Frequency]string{Daily: "day", Weekly: "week", Monthly: "month", Yearly: "year"}
+
+	var desc string
+	if r.Interval > 1 {
+		desc = fmt.Sprintf("every %d %ss", r.Interval, units[r.Freq])
+	} else {
+		desc = "every " + units[r.Freq]
+	}
+
+	if r.String() == "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR" {
+		return "every weekday"
+	}
+
+	if len(r.ByDay) > 0 {
+		names := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			name := d.Weekday.String()
+			switch {
+			case d.Ordinal == -1:
+				name = "the last " + name
+			case d.Ordinal > 0:
+				name = "the " + ordinalSuffix(d.Ordinal) + " " + name
+			}
+			names[i] = name
+		}
+		desc += " on " + strings.Join(names, ", ")
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			if d == -1 {
+				days[i] = "the last day"
+			} else {
+				days[i] = "the " + ordinalSuffix(d)
+			}
+		}
+		desc += " on " + strings.Join(days, ", ")
+	}
+
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = time.Month(m).String()
+		}
+		desc += " in " + strings.Join(months, ", ")
+	}
+
+	return desc
+}
+
+func ordinalSuffix(n int) string {
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+		return fmt.Sprintf("%dth", n)
+	case n%10 == 1:
+		return fmt.Sprintf("%dst", n)
+	case n%10 == 2:
+		return fmt.Sprintf("%dnd", n)
+	case n%10 == 3:
+		return fmt.Sprintf("%drd", n)
+	default:
+		return fmt.Sprintf("%dth", n)
+	}
+}
+
+// Next returns the first occurrence strictly after the given time, keeping
+// its time of day. The given time also anchors the interval.
+func (r *Recurrence) Next(after time.Time) time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	if r.Freq == Daily && len(r.ByDay) == 0 {
+		return after.AddDate(0, 0, interval)
+	}
+
+	// Walk forward one day at a time; bounded by the longest possible gap
+	limit := 370*interval + 370
+	for i := 1; i <= limit; i++ {
+		candidate := after.AddDate(0, 0, i)
+		if r.matches(candidate, after) {
+			return candidate
+		}
+	}
+	return after.AddDate(0, 0, limit)
+}
+
+func (r *Recurrence) matches(t, anchor time.Time) bool {
+	interval := max(r.Interval, 1)
+
+	switch r.Freq {
+	case Daily:
+		days := int(dayStart(t).Sub(dayStart(anchor)).Hours()+12) / 24
+		return days%interval == 0 && r.matchesWeekday(t)
+
+	case Weekly:
+		weeks := int(weekStart(t).Sub(weekStart(anchor)).Hours()+12) / (24 * 7)
+		if weeks%interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return t.Weekday() == anchor.Weekday()
+		}
+		return r.matchesWeekday(t)
+
+	case Monthly:
+		months := (t.Year()-anchor.Year())*12 + int(t.Month()-anchor.Month())
+		if months%interval != 0 {
+			return false
+		}
+		switch {
+		case len(r.ByDay) > 0:
+			return r.matchesWeekday(t)
+		case len(r.ByMonthDay) > 0:
+			for _, md := range r.ByMonthDay {
+				if t.Day() == resolveMonthDay(t, md) {
+					return true
+				}
+			}
+			return false
+		default:
+			return t.Day() == resolveMonthDay(t, anchor.Day())
+		}
+
+	case Yearly:
+		if (t.Year()-anchor.Year())%interval != 0 {
+			return false
+		}
+		if len(r.ByMonth) == 0 && t.Month() != anchor.Month() {
+			return false
+		}
+		if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, int(t.Month())) {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 {
+			return t.Day() == resolveMonthDay(t, anchor.Day())
+		}
+		for _, md := range r.ByMonthDay {
+			if t.Day() == resolveMonthDay(t, md) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return false
+}
+
+func (r *Recurrence) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if t.Weekday() != d.Weekday {
+			continue
+		}
+		switch {
+		case d.Ordinal == 0:
+			return true
+		case d.Ordinal > 0 && (t.Day()-1)/7+1 == d.Ordinal:
+			return true
+		case d.Ordinal == -1 && t.Day()+7 > daysInMonth(t):
+			return true
+		}
+	}
+	return false
+}
+
+// AddMonths adds n months to t, keeping its time of day. A day the target
+// month doesn't have is clamped to its last day rather than overflowing
+// into the next month, so Jan 31 plus one month is Feb 28, not Mar 3.
+func AddMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	return first.AddDate(0, 0, resolveMonthDay(first, t.Day())-1)
+}
+
+// resolveMonthDay maps a month day (negative counts from the end) onto t's
+// month, clamping days that don't exist (the 31st in April becomes the 30th)
+func resolveMonthDay(t time.Time, day int) int {
+	n := daysInMonth(t)
+	if day < 0 {
+		return n + day + 1
+	}
+	return min(day, n)
+}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // Monday=0
+	return dayStart(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextKeepsPinnedDay(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		first string
+		want  []string
+	}{
+		{
+			name:  "monthly from the 31st",
+			rule:  "every month",
+			first: "2026-01-31",
+			want:  []string{"2026-02-28", "2026-03-31", "2026-04-30", "2026-05-31"},
+		},
+		{
+			name:  "monthly from the 30th",
+			rule:  "FREQ=MONTHLY",
+			first: "2027-01-30",
+			want:  []string{"2027-02-28", "2027-03-30"},
+		},
+		{
+			name:  "every other month from the 31st",
+			rule:  "every 2 months",
+			first: "2026-12-31",
+			want:  []string{"2027-02-28", "2027-04-30", "2027-06-30", "2027-08-31"},
+		},
+		{
+			name:  "yearly from a leap day",
+			rule:  "yearly",
+			first: "2028-02-29",
+			want:  []string{"2029-02-28", "2030-02-28", "2031-02-28", "2032-02-29"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			due, _ := time.Parse("2006-01-02", tt.first)
+			rule.PinDay(due)
+
+			for _, want := range tt.want {
+				// The rule is stored and parsed again for every occurrence
+				if rule, err = ParseRecurrence(rule.String()); err != nil {
+					t.Fatal(err)
+				}
+				due = rule.Next(due)
+				if got := due.Format("2006-01-02"); got != want {
+					t.Fatalf("%s: got %s, want %s", rule, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPinDayKeepsExplicitDays(t *testing.T) {
+	due, _ := time.Parse("2006-01-02", "2026-01-31")
+	for rule, want := range map[string]string{
+		"monthly on the last friday": "FREQ=MONTHLY;BYDAY=-1FR",
+		"monthly on the 15th":        "FREQ=MONTHLY;BYMONTHDAY=15",
+		"every week":                 "FREQ=WEEKLY",
+		"every month":                "FREQ=MONTHLY;BYMONTHDAY=31",
+		"yearly":                     "FREQ=YEARLY;BYMONTH=1;BYMONTHDAY=31",
+	} {
+		r, err := ParseRecurrence(rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.PinDay(due)
+		if got := r.String(); got != want {
+			t.Errorf("%s: got %s, want %s", rule, got, want)
+		}
+	}
+}
+
+func TestParseRecurrenceRejectsIgnoredParts(t *testing.T) {
+	for _, rule := range []string{
+		// next would ignore the weekdays
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=YEARLY;BYMONTH=3;BYDAY=-1FR",
+		// and here the month days
+		"FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+		"FREQ=MONTHLY;BYMONTHDAY=1;BYDAY=1MO",
+		// ordinals, month days and months outside their frequencies
+		"FREQ=WEEKLY;BYDAY=2TU",
+		"FREQ=WEEKLY;BYMONTHDAY=15",
+		"FREQ=MONTHLY;BYMONTH=2",
+	} {
+		if r, err := ParseRecurrence(rule); err == nil {
+			t.Errorf("%s: accepted as %s", rule, r)
+		}
+	}
+
+	for _, rule := range []string{
+		"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+		"FREQ=WEEKLY;BYDAY=MO,TH",
+		"FREQ=MONTHLY;BYDAY=-1FR",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15",
+		"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29",
+	} {
+		if _, err := ParseRecurrence(rule); err != nil {
+			t.Errorf("%s: %v", rule, err)
+		}
+	}
+}
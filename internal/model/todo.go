@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -11,11 +12,74 @@ type Todo struct {
 	Description string     `json:"description,omitempty"`
 	Tags        []string   `json:"tags,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
+	DueHasTime  bool       `json:"due_has_time,omitempty"` // due at a time of day rather than by the end of the day
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
 	Completed   bool       `json:"completed"`
-	Priority    int        `json:"priority,omitempty"` // 1-5 (1=highest), 0=no priority
+	Priority    int        `json:"priority,omitempty"`   // 1-5 (1=highest), 0=no priority
+	Recurrence  string     `json:"recurrence,omitempty"` // RRULE, e.g. "FREQ=WEEKLY;BYDAY=MO"
+	ParentID    *int64     `json:"parent_id,omitempty"`
+	ProjectID   *int64     `json:"project_id,omitempty"`
+	Project     string     `json:"project,omitempty"`     // project name, read-only
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`  // set while in the trash
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"` // set once a completed todo is archived
+	Version     int64      `json:"version,omitempty"`     // bumped on every change, see storage.ConflictError
+
+	// Subtask and blocker counts are computed by storage and not persisted
+	SubtaskTotal int `json:"-"`
+	SubtaskDone  int `json:"-"`
+	OpenBlockers int `json:"-"`
+}
+
+// IsBlocked returns true if the todo depends on todos that are not completed yet
+func (t *Todo) IsBlocked() bool {
+	return t.OpenBlockers > 0
+}
+
+// IsDeleted returns true if the todo is in the trash
+func (t *Todo) IsDeleted() bool {
+	return t.DeletedAt != nil
+}
+
+// IsArchived returns true if the todo was moved out of the active working set
+func (t *Todo) IsArchived() bool {
+	return t.ArchivedAt != nil
+}
+
+// HasSubtasks returns true if the todo has child todos
+func (t *Todo) HasSubtasks() bool {
+	return t.SubtaskTotal > 0
+}
+
+// OpenSubtasks returns the number of children that are not completed
+func (t *Todo) OpenSubtasks() int {
+	return t.SubtaskTotal - t.SubtaskDone
+}
+
+// ProgressString returns subtask progress like "3/5 done", or "" without subtasks
+func (t *Todo) ProgressString() string {
+	if t.SubtaskTotal == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d done", t.SubtaskDone, t.SubtaskTotal)
+}
+
+// IsRecurring returns true if the todo repeats on a schedule
+func (t *Todo) IsRecurring() bool {
+	return t.Recurrence != ""
+}
+
+// RecurrenceRule returns the parsed recurrence rule, or nil if the todo doesn't repeat
+func (t *Todo) RecurrenceRule() *Recurrence {
+	if t.Recurrence == "" {
+		return nil
+	}
+	r, err := ParseRecurrence(t.Recurrence)
+	if err != nil {
+		return nil
+	}
+	return r
 }
 
 // IsOverdue returns true if the todo has a due date in the past and is not completed
@@ -32,7 +96,7 @@ func (t *Todo) IsDueToday() bool {
 		return false
 	}
 	now := time.Now()
-	y1, m1, d1 := t.DueDate.Date()
+	y1, m1, d1 := t.DueDate.Local().Date()
 	y2, m2, d2 := now.Date()
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
@@ -43,11 +107,24 @@ func (t *Todo) IsDueTomorrow() bool {
 		return false
 	}
 	tomorrow := time.Now().AddDate(0, 0, 1)
-	y1, m1, d1 := t.DueDate.Date()
+	y1, m1, d1 := t.DueDate.Local().Date()
 	y2, m2, d2 := tomorrow.Date()
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
+// FormatDue formats the due date in local time with the given date layout,
+// adding the time of day only if one was set
+func (t *Todo) FormatDue(dateLayout string) string {
+	if t.DueDate == nil {
+		return ""
+	}
+	due := t.DueDate.Local()
+	if t.DueHasTime {
+		return due.Format(dateLayout + " 15:04")
+	}
+	return due.Format(dateLayout)
+}
+
 // IsDueWithinDays returns true if the todo is due within the given number of days
 func (t *Todo) IsDueWithinDays(days int) bool {
 	if t.DueDate == nil {
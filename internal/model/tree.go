@@ -0,0 +1,50 @@
+package model
+
+// TreeItem is a todo positioned in the parent/child hierarchy
+type TreeItem struct {
+	Todo  Todo
+	Depth int
+}
+
+// BuildTree orders todos so that children follow their parent, keeping the
+// relative order of siblings. Todos whose parent is not in the slice are
+// treated as roots. Descendants of todos in collapsed are left out.
+func BuildTree(todos []Todo, collapsed map[int64]bool) []TreeItem {
+	present := make(map[int64]bool, len(todos))
+	for _, t := range todos {
+		present[t.ID] = true
+	}
+
+	children := make(map[int64][]Todo)
+	var roots []Todo
+	for _, t := range todos {
+		if t.ParentID != nil && present[*t.ParentID] && *t.ParentID != t.ID {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	items := make([]TreeItem, 0, len(todos))
+	visited := make(map[int64]bool, len(todos))
+	var walk func(t Todo, depth int)
+	walk = func(t Todo, depth int) {
+		if visited[t.ID] {
+			return
+		}
+		visited[t.ID] = true
+		items = append(items, TreeItem{Todo: t, Depth: depth})
+		if collapsed[t.ID] {
+			return
+		}
+		for _, child := range children[t.ID] {
+			walk(child, depth+1)
+		}
+	}
+
+	for _, t := range roots {
+		walk(t, 0)
+	}
+
+	return items
+}
@@ -0,0 +1,106 @@
+// Package query implements the filter language used by todo list -q, e.g.
+//
+//	priority <= 2 and (tag:#work or tag:#oncall) and due < +3d
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a node of a parsed filter expression
+type Expr interface {
+	// Pos is the 1-based column where the expression starts
+	Pos() int
+	String() string
+}
+
+// BoolOp joins two expressions
+type BoolOp string
+
+const (
+	And BoolOp = "and"
+	Or  BoolOp = "or"
+)
+
+// Binary is an and/or of two expressions
+type Binary struct {
+	Op          BoolOp
+	Left, Right Expr
+}
+
+// Not negates an expression
+type Not struct {
+	X  Expr
+	At int
+}
+
+// Cond compares a field against a value, e.g. priority <= 2
+type Cond struct {
+	Field   Field
+	Op      Op
+	Value   string
+	At      int
+	ValueAt int
+}
+
+// Text is a bare word or "quoted phrase", matched with full-text search
+type Text struct {
+	Value  string
+	Phrase bool
+	At     int
+}
+
+func (b *Binary) Pos() int { return b.Left.Pos() }
+func (n *Not) Pos() int    { return n.At }
+func (c *Cond) Pos() int   { return c.At }
+func (t *Text) Pos() int   { return t.At }
+
+func (b *Binary) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.Left, b.Op, b.Right)
+}
+
+func (n *Not) String() string {
+	return "not " + n.X.String()
+}
+
+func (c *Cond) String() string {
+	return fmt.Sprintf("%s %s %s", c.Field, c.Op, quote(c.Value))
+}
+
+func (t *Text) String() string {
+	if t.Phrase {
+		return `"` + t.Value + `"`
+	}
+	return quote(t.Value)
+}
+
+func quote(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t()\"=!<>:") {
+		return `"` + s + `"`
+	}
+	return s
+}
+
+// Walk calls fn for every node of expr, parents first
+func Walk(expr Expr, fn func(Expr)) {
+	fn(expr)
+	switch e := expr.(type) {
+	case *Binary:
+		Walk(e.Left, fn)
+		Walk(e.Right, fn)
+	case *Not:
+		Walk(e.X, fn)
+	}
+}
+
+// Uses reports whether expr has a condition on field
+func Uses(expr Expr, field Field) bool {
+	found := false
+	Walk(expr, func(e Expr) {
+		if c, ok := e.(*Cond); ok && c.Field == field {
+			found = true
+		}
+	})
+	return found
+}
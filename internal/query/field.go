@@ -0,0 +1,114 @@
+package query
+
+// Field is a todo attribute that can appear in a condition
+type Field string
+
+const (
+	FieldID        Field = "id"
+	FieldPriority  Field = "priority"
+	FieldDue       Field = "due"
+	FieldCreated   Field = "created"
+	FieldUpdated   Field = "updated"
+	FieldCompleted Field = "completed"
+	FieldTag       Field = "tag"
+	FieldProject   Field = "project"
+	FieldTitle     Field = "title"
+	FieldDesc      Field = "desc"
+	FieldStatus    Field = "status"
+)
+
+// Op is a comparison operator
+type Op string
+
+const (
+	OpEq    Op = "="
+	OpNe    Op = "!="
+	OpLt    Op = "<"
+	OpLe    Op = "<="
+	OpGt    Op = ">"
+	OpGe    Op = ">="
+	OpMatch Op = ":"
+)
+
+// None is the value matching an unset field, e.g. due:none
+const None = "none"
+
+// Kind is the type of value a field holds
+type Kind int
+
+const (
+	KindNumber Kind = iota
+	KindDate
+	KindName
+	KindText
+	KindStatus
+)
+
+// Fields lists the known fields, in the order they are documented
+var Fields = []Field{
+	FieldStatus, FieldPriority, FieldDue, FieldCreated, FieldUpdated, FieldCompleted,
+	FieldTag, FieldProject, FieldTitle, FieldDesc, FieldID,
+}
+
+var fieldKinds = map[Field]Kind{
+	FieldID:        KindNumber,
+	FieldPriority:  KindNumber,
+	FieldDue:       KindDate,
+	FieldCreated:   KindDate,
+	FieldUpdated:   KindDate,
+	FieldCompleted: KindDate,
+	FieldTag:       KindName,
+	FieldProject:   KindName,
+	FieldTitle:     KindText,
+	FieldDesc:      KindText,
+	FieldStatus:    KindStatus,
+}
+
+var fieldAliases = map[string]Field{
+	"p":           FieldPriority,
+	"pri":         FieldPriority,
+	"tags":        FieldTag,
+	"description": FieldDesc,
+	"is":          FieldStatus,
+}
+
+// PriorityNames maps priority names to their levels
+var PriorityNames = map[string]int{
+	"urgent": 1,
+	"high":   2,
+	"medium": 3,
+	"low":    4,
+	"lowest": 5,
+}
+
+// Statuses lists the values accepted by status:
+var Statuses = []string{"open", "done", "blocked", "ready", "overdue"}
+
+var statusAliases = map[string]string{
+	"pending":   "open",
+	"completed": "done",
+}
+
+// Kind returns the kind of value the field holds
+func (f Field) Kind() Kind {
+	return fieldKinds[f]
+}
+
+func lookupField(name string) (Field, bool) {
+	if f, ok := fieldAliases[name]; ok {
+		return f, true
+	}
+	f := Field(name)
+	_, ok := fieldKinds[f]
+	return f, ok
+}
+
+// allowedOps lists the operators each kind of field accepts
+func allowedOps(kind Kind) []Op {
+	switch kind {
+	case KindNumber, KindDate:
+		return []Op{OpMatch, OpEq, OpNe, OpLt, OpLe, OpGt, OpGe}
+	default:
+		return []Op{OpMatch, OpEq, OpNe}
+	}
+}
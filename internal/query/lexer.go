@@ -0,0 +1,127 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 1-based column
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokString:
+		return fmt.Sprintf("%q", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+// Error is a syntax or value error at a column of the query
+type Error struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Pos, e.Msg)
+}
+
+// Caret renders the query with a marker under the offending column
+func (e *Error) Caret() string {
+	return e.Query + "\n" + strings.Repeat(" ", max(e.Pos-1, 0)) + "^"
+}
+
+// Errorf returns an Error for the expression at pos
+func Errorf(query string, pos int, format string, args ...interface{}) *Error {
+	return &Error{Query: query, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func isOpChar(r rune) bool {
+	return r == '=' || r == '!' || r == '<' || r == '>' || r == ':'
+}
+
+func isWordChar(r rune) bool {
+	return !unicode.IsSpace(r) && !isOpChar(r) && r != '(' && r != ')' && r != '"'
+}
+
+func lex(query string) ([]token, error) {
+	var tokens []token
+	runes := []rune(query)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokRParen, ")", pos})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, Errorf(query, pos, "unterminated string")
+			}
+			tokens = append(tokens, token{tokString, string(runes[i+1 : end]), pos})
+			i = end + 1
+		case r == '!' && (i+1 == len(runes) || runes[i+1] != '='):
+			tokens = append(tokens, token{tokNot, "!", pos})
+			i++
+		case isOpChar(r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != ':' && r != '=' {
+				op += "="
+			}
+			tokens = append(tokens, token{tokOp, op, pos})
+			i += len(op)
+		default:
+			end := i
+			for end < len(runes) && isWordChar(runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			kind := tokWord
+			switch strings.ToLower(word) {
+			case "and", "&&":
+				kind = tokAnd
+			case "or", "||":
+				kind = tokOr
+			case "not":
+				kind = tokNot
+			}
+			tokens = append(tokens, token{kind, word, pos})
+			i = end
+		}
+	}
+
+	return append(tokens, token{tokEOF, "", len(runes) + 1}), nil
+}
@@ -0,0 +1,220 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parse parses a filter expression. Conditions are written field op value,
+// with op one of = != < <= > >= or : (match). Conditions can be combined with
+// and, or, not and parentheses; and is implied between adjacent terms. Bare
+// words and "quoted phrases" are full-text search terms.
+func Parse(query string) (Expr, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{query: query, tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, Errorf(query, 1, "empty query")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.kind == tokRParen {
+			return nil, Errorf(query, tok.pos, "unmatched ')'")
+		}
+		return nil, Errorf(query, tok.pos, "unexpected %s", tok.describe())
+	}
+
+	return expr, nil
+}
+
+type parser struct {
+	query  string
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokOr {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: Or, Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokWord, tokString, tokNot, tokLParen:
+			// implicit and
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: And, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if tok := p.peek(); tok.kind == tokNot {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x, At: tok.pos}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, Errorf(p.query, closing.pos, "expected ')' to close '(' at column %d, found %s", tok.pos, closing.describe())
+		}
+		return expr, nil
+	case tokString:
+		if p.peek().kind == tokOp {
+			return nil, Errorf(p.query, tok.pos, "expected a field name before %s", p.peek().describe())
+		}
+		return &Text{Value: tok.text, Phrase: true, At: tok.pos}, nil
+	case tokWord:
+		if p.peek().kind != tokOp {
+			return &Text{Value: tok.text, At: tok.pos}, nil
+		}
+		return p.parseCond(tok)
+	case tokEOF:
+		return nil, Errorf(p.query, tok.pos, "unexpected end of query, expected a condition")
+	default:
+		return nil, Errorf(p.query, tok.pos, "unexpected %s, expected a condition", tok.describe())
+	}
+}
+
+func (p *parser) parseCond(name token) (Expr, error) {
+	field, ok := lookupField(strings.ToLower(name.text))
+	if !ok {
+		return nil, Errorf(p.query, name.pos, "unknown field %q (fields: %s)", name.text, fieldList())
+	}
+
+	opTok := p.next()
+	op := Op(opTok.text)
+	if !containsOp(allowedOps(field.Kind()), op) {
+		return nil, Errorf(p.query, opTok.pos, "%s does not support '%s'", field, op)
+	}
+
+	value := p.next()
+	if value.kind != tokWord && value.kind != tokString {
+		return nil, Errorf(p.query, value.pos, "expected a value after '%s', found %s", op, value.describe())
+	}
+
+	cond := &Cond{Field: field, Op: op, Value: value.text, At: name.pos, ValueAt: value.pos}
+	if err := p.checkValue(cond); err != nil {
+		return nil, err
+	}
+	return cond, nil
+}
+
+// checkValue validates values whose meaning doesn't depend on storage
+func (p *parser) checkValue(c *Cond) error {
+	isNone := strings.EqualFold(c.Value, None)
+	if isNone && c.Op != OpEq && c.Op != OpNe && c.Op != OpMatch {
+		return Errorf(p.query, c.ValueAt, "'none' can only be compared with :, = or !=")
+	}
+
+	switch c.Field.Kind() {
+	case KindNumber:
+		if isNone && c.Field == FieldPriority {
+			c.Value = None
+			return nil
+		}
+		if _, err := strconv.Atoi(c.Value); err == nil {
+			return nil
+		}
+		if level, ok := PriorityNames[strings.ToLower(c.Value)]; ok && c.Field == FieldPriority {
+			c.Value = strconv.Itoa(level)
+			return nil
+		}
+		return Errorf(p.query, c.ValueAt, "%s expects a number, got %q", c.Field, c.Value)
+	case KindStatus:
+		status := strings.ToLower(c.Value)
+		if alias, ok := statusAliases[status]; ok {
+			status = alias
+		}
+		for _, s := range Statuses {
+			if s == status {
+				c.Value = status
+				return nil
+			}
+		}
+		return Errorf(p.query, c.ValueAt, "unknown status %q (expected %s)", c.Value, strings.Join(Statuses, ", "))
+	case KindDate, KindName:
+		if isNone {
+			c.Value = None
+		}
+	}
+
+	return nil
+}
+
+func containsOp(ops []Op, op Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldList() string {
+	names := make([]string, len(Fields))
+	for i, f := range Fields {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
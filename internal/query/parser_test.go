@@ -0,0 +1,150 @@
+package query
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		// Conditions, with aliases and values normalized
+		{"priority <= 2", "priority <= 2"},
+		{"p:high", "priority : 2"},
+		{"pri != urgent", "priority != 1"},
+		{"due < +3d", "due < +3d"},
+		{"tags:#work", "tag : #work"},
+		{"description:bug", "desc : bug"},
+		{"is:pending", "status : open"},
+		{"status = completed", "status = done"},
+		{`title:"release notes"`, `title : "release notes"`},
+		{"ID >= 10", "id >= 10"},
+
+		// none matches unset fields, in any case
+		{"due:none", "due : none"},
+		{"project = NONE", "project = none"},
+		{"tag != None", "tag != none"},
+		{"priority:none", "priority : none"},
+
+		// and binds tighter than or, and is implied, both associate left
+		{"a or b and c", "(a or (b and c))"},
+		{"a and b or c", "((a and b) or c)"},
+		{"a b c", "((a and b) and c)"},
+		{"a or b or c", "((a or b) or c)"},
+		{"(a or b) c", "((a or b) and c)"},
+		{"a && b || c", "((a and b) or c)"},
+		{"due < today or priority:1 tag:#work", "(due < today or (priority : 1 and tag : #work))"},
+
+		// not binds tightest
+		{"not a b", "(not a and b)"},
+		{"!a or b", "(not a or b)"},
+		{"not (a or b)", "not (a or b)"},
+		{"not not tag:#x", "not not tag : #x"},
+		{"!status:done", "not status : done"},
+
+		// Full-text terms
+		{"deploy", "deploy"},
+		{`"on call"`, `"on call"`},
+		{`deploy "on call" project:web`, `((deploy and "on call") and project : web)`},
+
+		// != is an operator, not a negated =
+		{"priority!=3", "priority != 3"},
+	}
+
+	for _, tt := range tests {
+		expr, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		if got := expr.String(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"", 1, "empty query"},
+		{"   ", 1, "empty query"},
+		{"priority <= ", 13, "expected a value after '<='"},
+		{"colour:red", 1, `unknown field "colour"`},
+		{"tag < #work", 5, "tag does not support '<'"},
+		{"title >= x", 7, "title does not support '>='"},
+		{"priority:soon", 10, `priority expects a number, got "soon"`},
+		{"id = seven", 6, `id expects a number, got "seven"`},
+		{"status:later", 8, `unknown status "later"`},
+		{"due < none", 7, "'none' can only be compared with :, = or !="},
+		{`title:"open`, 7, "unterminated string"},
+		{"(a or b", 8, "expected ')' to close '(' at column 1, found end of query"},
+		{"a or b)", 7, "unmatched ')'"},
+		{"a and", 6, "unexpected end of query, expected a condition"},
+		{"a or or b", 6, "unexpected 'or', expected a condition"},
+		{`"phrase":x`, 1, "expected a field name before ':'"},
+		{"not", 4, "unexpected end of query"},
+		{"() a", 2, "unexpected ')', expected a condition"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var qerr *Error
+		if !errors.As(err, &qerr) {
+			t.Errorf("%q: got %v, want a query error", tt.query, err)
+			continue
+		}
+		if qerr.Pos != tt.pos || !strings.Contains(qerr.Msg, tt.msg) {
+			t.Errorf("%q: got column %d %q, want column %d %q", tt.query, qerr.Pos, qerr.Msg, tt.pos, tt.msg)
+		}
+		if qerr.Query != tt.query {
+			t.Errorf("%q: error carries query %q", tt.query, qerr.Query)
+		}
+	}
+}
+
+func TestCaret(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"colour:red", "colour:red\n^"},
+		{"priority:soon", "priority:soon\n         ^"},
+		{"a or b)", "a or b)\n      ^"},
+		// Columns count characters, not bytes
+		{"tâche ünd tag < x", "tâche ünd tag < x\n              ^"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var qerr *Error
+		if !errors.As(err, &qerr) {
+			t.Fatalf("%q: got %v, want a query error", tt.query, err)
+		}
+		if got := qerr.Caret(); got != tt.want {
+			t.Errorf("%q: got caret\n%s\nwant\n%s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestUses(t *testing.T) {
+	expr, err := Parse("deploy or not (status:done and due < today)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for field, want := range map[Field]bool{
+		FieldStatus:   true,
+		FieldDue:      true,
+		FieldPriority: false,
+		FieldTitle:    false,
+	} {
+		if got := Uses(expr, field); got != want {
+			t.Errorf("Uses(%s): got %v, want %v", field, got, want)
+		}
+	}
+}
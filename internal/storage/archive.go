@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// SettingAutoArchive holds the age (e.g. "30d") after which completed todos
+// are archived automatically; empty disables auto-archiving
+const SettingAutoArchive = "archive.auto_after"
+
+// Archive moves the todos completed before the given time out of the active
+// working set and returns how many were archived. Archived todos are hidden
+// from List unless Filter.IncludeArchived is set, but remain searchable.
+func (s *SQLiteStorage) Archive(completedBefore time.Time) (int, error) {
+	return s.archive(completedBefore, true)
+}
+
+// AutoArchive archives like Archive, but leaves no undo log entry: it runs
+// in the background, and undo should revert the user's last change rather
+// than it.
+func (s *SQLiteStorage) AutoArchive(completedBefore time.Time) (int, error) {
+	return s.archive(completedBefore, false)
+}
+
+func (s *SQLiteStorage) archive(completedBefore time.Time, journaled bool) (int, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	todos, err := selectTodos(tx, `
+		SELECT `+todoColumns+` FROM todos
+		WHERE completed = 1 AND completed_at < ? AND archived_at IS NULL AND deleted_at IS NULL
+		ORDER BY id
+	`, completedBefore.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to load completed todos: %w", err)
+	}
+	if len(todos) == 0 {
+		return 0, nil
+	}
+
+	var j *journal
+	if journaled {
+		if j, err = s.beginJournal(tx, fmt.Sprintf("archive %d completed todo(s)", len(todos))); err != nil {
+			return 0, err
+		}
+	}
+
+	now := time.Now().UTC()
+	for i := range todos {
+		t := &todos[i]
+		if j != nil {
+			if err := j.capture(tx, t.ID); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.Exec("UPDATE todos SET archived_at = ?, version = version + 1 WHERE id = ?", now, t.ID); err != nil {
+			return 0, fmt.Errorf("failed to archive todo #%d: %w", t.ID, err)
+		}
+		after := *t
+		after.ArchivedAt = &now
+		if err := recordChanges(tx, EventUpdate, t.ID, t, &after); err != nil {
+			return 0, err
+		}
+	}
+
+	if j != nil {
+		if err := j.finish(tx); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit archive: %w", err)
+	}
+
+	return len(todos), nil
+}
+
+// Setting returns a stored setting, or "" if it was never set
+func (s *SQLiteStorage) Setting(key string) (string, error) {
+	var value string
+	err := s.conn().QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get setting %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetSetting stores a setting; an empty value removes it
+func (s *SQLiteStorage) SetSetting(key, value string) error {
+	var err error
+	if value == "" {
+		_, err = s.conn().Exec("DELETE FROM settings WHERE key = ?", key)
+	} else {
+		_, err = s.conn().Exec(`
+			INSERT INTO settings (key, value) VALUES (?, ?)
+			ON CONFLICT (key) DO UPDATE SET value = excluded.value
+		`, key, value)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save setting %s: %w", key, err)
+	}
+	return nil
+}
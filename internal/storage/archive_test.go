@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+func TestArchivedSubtasksAreNotCounted(t *testing.T) {
+	s := newTestStorage(t)
+	parent := mustCreate(t, s, &model.Todo{Title: "release"})
+	for _, title := range []string{"changelog", "tag"} {
+		child := mustCreate(t, s, &model.Todo{Title: title, ParentID: &parent.ID})
+		if _, err := Complete(s, child, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustCreate(t, s, &model.Todo{Title: "announce", ParentID: &parent.ID})
+
+	if n, err := s.Archive(time.Now().Add(time.Second)); err != nil || n != 2 {
+		t.Fatalf("archived %d todos (%v), want 2", n, err)
+	}
+
+	// The counts agree with the subtasks List shows
+	children, err := s.List(Filter{ParentID: &parent.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := mustGet(t, s, parent.ID)
+	if got.SubtaskTotal != len(children) || got.SubtaskTotal != 1 || got.SubtaskDone != 0 {
+		t.Errorf("got %s of %d subtasks, listed %d, want 0/1", got.ProgressString(), got.SubtaskTotal, len(children))
+	}
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// ErrOpenSubtasks is returned when completing a todo whose children are still open
+var ErrOpenSubtasks = Errorf(ErrConflict, "todo has open subtasks")
+
+// Complete marks a todo as completed. If the todo is recurring, the next
+// occurrence is created with its due date moved forward and returned; the
+// recurrence rule moves to the new instance so reopening and completing the
+// old one again doesn't spawn duplicates.
+//
+// A todo with open subtasks is only completed when cascade is set, in which
+// case all of its open descendants are completed first.
+//
+// All of it happens in one transaction and is undone as one operation.
+func Complete(s Storage, todo *model.Todo, cascade bool) (*model.Todo, error) {
+	var next *model.Todo
+	err := s.Batch(fmt.Sprintf("complete #%d %q", todo.ID, todo.Title), func(tx Storage) error {
+		var err error
+		next, err = complete(tx, todo, cascade)
+		return err
+	})
+	return next, err
+}
+
+func complete(s Storage, todo *model.Todo, cascade bool) (*model.Todo, error) {
+	if err := completeSubtasks(s, todo, cascade); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	todo.Completed = true
+	todo.CompletedAt = &now
+
+	rule := todo.RecurrenceRule()
+	if rule == nil {
+		if err := s.Update(todo); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// Keep repeating on the day the todo was first due, see PinDay
+	if todo.DueDate != nil {
+		rule.PinDay(todo.DueDate.In(time.Local))
+	}
+
+	next := &model.Todo{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Tags:        todo.Tags,
+		Priority:    todo.Priority,
+		Recurrence:  rule.String(),
+		ParentID:    todo.ParentID,
+		ProjectID:   todo.ProjectID,
+		DueHasTime:  todo.DueHasTime && todo.DueDate != nil,
+	}
+	due := NextDueDate(rule, todo.DueDate, time.Now())
+	next.DueDate = &due
+
+	todo.Recurrence = ""
+	if err := s.Update(todo); err != nil {
+		return nil, err
+	}
+
+	if err := s.Create(next); err != nil {
+		return nil, fmt.Errorf("failed to create next occurrence: %w", err)
+	}
+
+	return next, nil
+}
+
+func completeSubtasks(s Storage, todo *model.Todo, cascade bool) error {
+	pending := false
+	children, err := s.List(Filter{ParentID: &todo.ID, Completed: &pending})
+	if err != nil {
+		return fmt.Errorf("failed to load subtasks: %w", err)
+	}
+	if len(children) == 0 {
+		return nil
+	}
+	if !cascade {
+		return fmt.Errorf("%w: #%d has %d open subtask(s)", ErrOpenSubtasks, todo.ID, len(children))
+	}
+
+	for i := range children {
+		if _, err := complete(s, &children[i], true); err != nil {
+			return fmt.Errorf("failed to complete subtask #%d: %w", children[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// NextDueDate returns the first occurrence of rule after the current due date
+// that is not already in the past. Todos without a due date are scheduled
+// relative to the end of today.
+func NextDueDate(rule *model.Recurrence, due *time.Time, now time.Time) time.Time {
+	base := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+	if due != nil {
+		base = due.In(now.Location())
+	}
+
+	next := rule.Next(base)
+	for next.Before(now) {
+		next = rule.Next(next)
+	}
+	return next
+}
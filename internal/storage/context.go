@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+)
+
+// WithContext returns a storage whose calls run under ctx: they stop with
+// ctx.Err() once it is cancelled or its deadline passes, rolling back any
+// change in progress. The returned storage shares the database connection.
+func (s *SQLiteStorage) WithContext(ctx context.Context) Storage {
+	bound := *s
+	bound.ctx = ctx
+	return &bound
+}
+
+func (s *SQLiteStorage) context() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// ctxConn runs queries on a database or transaction under a context
+type ctxConn struct {
+	ctx context.Context
+	q   interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	}
+}
+
+func (c ctxConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.q.ExecContext(c.ctx, query, args...)
+}
+
+func (c ctxConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.q.QueryContext(c.ctx, query, args...)
+}
+
+func (c ctxConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.q.QueryRowContext(c.ctx, query, args...)
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+
+	"todo_cli/internal/model"
+)
+
+// AddDependency records that todoID cannot start until blockedByID is done.
+// Edges that would create a cycle are rejected.
+func (s *SQLiteStorage) AddDependency(todoID, blockedByID int64) error {
+	if todoID == blockedByID {
+		return Errorf(ErrInvalid, "todo #%d cannot block itself", todoID)
+	}
+
+	// Check and insert in one transaction, so a concurrent change can't
+	// close a cycle in between
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range []int64{todoID, blockedByID} {
+		todo, err := loadTodo(tx, id)
+		if err != nil {
+			return err
+		}
+		if todo.IsDeleted() {
+			return Errorf(ErrConflict, "todo #%d is in the trash", id)
+		}
+	}
+
+	// Adding todo -> blocker closes a cycle if the blocker already
+	// (transitively) waits on todo
+	var path sql.NullString
+	err = tx.QueryRow(`
+		WITH RECURSIVE chain(id, path) AS (
+			SELECT blocked_by_id, '#' || todo_id || ' -> #' || blocked_by_id
+			FROM todo_dependencies WHERE todo_id = ?
+			UNION
+			SELECT d.blocked_by_id, c.path || ' -> #' || d.blocked_by_id
+			FROM todo_dependencies d JOIN chain c ON d.todo_id = c.id
+		)
+		SELECT path FROM chain WHERE id = ? LIMIT 1
+	`, blockedByID, todoID).Scan(&path)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check for cycles: %w", err)
+	}
+	if path.Valid {
+		return Errorf(ErrInvalid, "#%d cannot be blocked by #%d: it would create a cycle (#%d -> %s)",
+			todoID, blockedByID, todoID, path.String)
+	}
+
+	j, err := s.beginJournal(tx, fmt.Sprintf("block #%d on #%d", todoID, blockedByID))
+	if err != nil {
+		return err
+	}
+	if err := j.capture(tx, todoID, blockedByID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(
+		"INSERT OR IGNORE INTO todo_dependencies (todo_id, blocked_by_id) VALUES (?, ?)",
+		todoID, blockedByID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add dependency: %w", err)
+	}
+
+	if n, _ := result.RowsAffected(); n > 0 {
+		event := Event{TodoID: todoID, Op: EventUpdate, Field: "blocked_by", NewValue: fmt.Sprintf("#%d", blockedByID)}
+		if err := recordEvent(tx, event); err != nil {
+			return err
+		}
+	}
+	if err := j.finish(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RemoveDependency deletes the edge between todoID and blockedByID
+func (s *SQLiteStorage) RemoveDependency(todoID, blockedByID int64) error {
+	tx, err := s.begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	j, err := s.beginJournal(tx, fmt.Sprintf("unblock #%d from #%d", todoID, blockedByID))
+	if err != nil {
+		return err
+	}
+	if err := j.capture(tx, todoID, blockedByID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(
+		"DELETE FROM todo_dependencies WHERE todo_id = ? AND blocked_by_id = ?",
+		todoID, blockedByID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove dependency: %w", err)
+	}
+
+	if err := expectRow(result, fmt.Sprintf("todo #%d is not blocked by #%d", todoID, blockedByID)); err != nil {
+		return err
+	}
+
+	event := Event{TodoID: todoID, Op: EventUpdate, Field: "blocked_by", OldValue: fmt.Sprintf("#%d", blockedByID)}
+	if err := recordEvent(tx, event); err != nil {
+		return err
+	}
+	if err := j.finish(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Blockers returns the todos that todoID waits on
+func (s *SQLiteStorage) Blockers(todoID int64) ([]model.Todo, error) {
+	return s.queryTodos(
+		"SELECT "+todoColumns+" FROM todos WHERE deleted_at IS NULL AND id IN (SELECT blocked_by_id FROM todo_dependencies WHERE todo_id = ?) ORDER BY id",
+		todoID,
+	)
+}
+
+// Dependents returns the todos that wait on todoID
+func (s *SQLiteStorage) Dependents(todoID int64) ([]model.Todo, error) {
+	return s.queryTodos(
+		"SELECT "+todoColumns+" FROM todos WHERE deleted_at IS NULL AND id IN (SELECT todo_id FROM todo_dependencies WHERE blocked_by_id = ?) ORDER BY id",
+		todoID,
+	)
+}
+
+func (s *SQLiteStorage) queryTodos(query string, args ...interface{}) ([]model.Todo, error) {
+	return selectTodos(s.conn(), query, args...)
+}
+
+func selectTodos(q dbtx, query string, args ...interface{}) ([]model.Todo, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query todos: %w", err)
+	}
+	defer rows.Close()
+
+	var todos []model.Todo
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan todo: %w", err)
+		}
+		todos = append(todos, *todo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating todos: %w", err)
+	}
+
+	return todos, nil
+}
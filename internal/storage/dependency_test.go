@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"todo_cli/internal/model"
+)
+
+func TestAddDependencyCycles(t *testing.T) {
+	s := newTestStorage(t)
+	todo := make(map[string]int64)
+	for _, title := range []string{"a", "b", "c", "d"} {
+		todo[title] = mustCreate(t, s, &model.Todo{Title: title}).ID
+	}
+	block := func(blocked, blocker string) error {
+		return s.AddDependency(todo[blocked], todo[blocker])
+	}
+
+	// a waits on b, b on c; d waits on both a and c, which is no cycle
+	for _, edge := range [][2]string{{"a", "b"}, {"b", "c"}, {"d", "a"}, {"d", "c"}} {
+		if err := block(edge[0], edge[1]); err != nil {
+			t.Fatalf("%s blocked by %s: %v", edge[0], edge[1], err)
+		}
+	}
+	// Adding an edge again changes nothing
+	if err := block("a", "b"); err != nil {
+		t.Errorf("a blocked by b again: %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		blocked, blocker string
+		cycle            []string // as reported, if there is only one
+	}{
+		{"self", "a", "a", nil},
+		{"direct", "b", "a", []string{"b", "a", "b"}},
+		{"transitive", "c", "a", []string{"c", "a", "b", "c"}},
+		{"through either side of a diamond", "c", "d", nil},
+	}
+	for _, tt := range tests {
+		err := block(tt.blocked, tt.blocker)
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: got %v, want an invalid dependency", tt.name, err)
+			continue
+		}
+		if tt.cycle == nil {
+			continue
+		}
+		steps := make([]string, len(tt.cycle))
+		for i, title := range tt.cycle {
+			steps[i] = fmt.Sprintf("#%d", todo[title])
+		}
+		if cycle := "(" + strings.Join(steps, " -> ") + ")"; !strings.Contains(err.Error(), cycle) {
+			t.Errorf("%s: got %q, want the cycle %s", tt.name, err, cycle)
+		}
+	}
+
+	// No rejected edge was stored
+	blockers, err := s.Blockers(todo["c"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blockers) != 0 {
+		t.Errorf("c is blocked by %v, want nothing", ids(blockers))
+	}
+}
+
+func TestAddDependencyCycleInBatch(t *testing.T) {
+	s := newTestStorage(t)
+	a := mustCreate(t, s, &model.Todo{Title: "a"})
+	b := mustCreate(t, s, &model.Todo{Title: "b"})
+
+	// The check sees edges added earlier in the same transaction
+	err := s.Batch("block both ways", func(tx Storage) error {
+		if err := tx.AddDependency(a.ID, b.ID); err != nil {
+			return err
+		}
+		return tx.AddDependency(b.ID, a.ID)
+	})
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("got %v, want an invalid dependency", err)
+	}
+
+	// and the failed batch left nothing behind
+	for _, id := range []int64{a.ID, b.ID} {
+		blockers, err := s.Blockers(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(blockers) != 0 {
+			t.Errorf("#%d is blocked by %v, want nothing", id, ids(blockers))
+		}
+	}
+}
+
+func TestAddDependencyInTrash(t *testing.T) {
+	s := newTestStorage(t)
+	a := mustCreate(t, s, &model.Todo{Title: "a"})
+	b := mustCreate(t, s, &model.Todo{Title: "b"})
+	if err := s.Delete(b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AddDependency(a.ID, b.ID); !errors.Is(err, ErrConflict) {
+		t.Errorf("blocking on a deleted todo: got %v, want a conflict", err)
+	}
+	if err := s.AddDependency(a.ID, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("blocking on a missing todo: got %v, want not found", err)
+	}
+	if blockers, _ := s.Blockers(a.ID); len(blockers) != 0 {
+		t.Errorf("a is blocked by %v, want nothing", ids(blockers))
+	}
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// Zone policy: timestamps are stored in UTC. Due dates are parsed and shown
+// in time.Local, which the --tz flag overrides. A due date without a time of
+// day means the end of that day in the zone it was set in.
+
+// DateGrammar describes the dates ParseDue accepts, for help texts
+const DateGrammar = `Dates:
+  2026-02-14, 2026/02/14, 02/14/2026, Feb 14, 2026   a calendar date
+  today, tomorrow, yesterday
+  mon, friday                  the next one, today included
+  next friday                  that day of next week (weeks start on Monday)
+  next week, next month        a week or a month from today
+  in 3 days, in 2 weeks        also months and years
+  +3d, -1w, +2m, +1y           offsets from today
+  eow, eom, eoq, eoy           the end of this week, month, quarter or year,
+                               also "end of month" and so on
+  q2, 2026-q2                  the end of a quarter; without a year the next
+                               one to end
+  2026-W14                     the end (Sunday) of an ISO week
+
+Any of them can be followed by a time: 14:30, 9am, 5:15 pm, "at 17:00".`
+
+// dueTime matches a time of day at the end of a due date: 14:30, 9am,
+// 9:15 pm, optionally after "at"
+var dueTime = regexp.MustCompile(`^(?:(.*?)\s+(?:at\s+)?)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+
+var (
+	relativeDate = regexp.MustCompile(`^([+-])(\d+)([dwmy])$`)
+	inDate       = regexp.MustCompile(`^in\s+(\d+|an?)\s*([a-z]*)$`)
+	isoWeekDate  = regexp.MustCompile(`^(\d{4})-?w(\d{1,2})$`)
+	quarterDate  = regexp.MustCompile(`^(?:(\d{4})-?)?q(\d)$`)
+	numericDate  = regexp.MustCompile(`^\d{4}[-/]\d{1,2}[-/]\d{1,2}$`)
+)
+
+// dueDateFormats are the date layouts ParseDue accepts
+var dueDateFormats = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"01-02-2006",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tues": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseDue parses a due date with an optional time of day, such as
+// "2026-03-01", "2026-03-01 14:30", "tomorrow 9am", "next friday 17:00" or
+// "17:00" (today); see DateGrammar. hasTime reports whether a time was
+// given; without one the due date is the end of the day.
+func ParseDue(s string) (due *time.Time, hasTime bool, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false, nil
+	}
+	now := time.Now().In(time.Local)
+
+	// ISO 8601 style, e.g. 2026-03-01T14:30
+	for _, layout := range []string{"2006-01-02T15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, true, nil
+		}
+	}
+
+	datePart, hour, minute, ok := splitDueTime(strings.ToLower(s))
+	if !ok {
+		day, err := parseDueDay(s, now)
+		if err != nil {
+			return nil, false, err
+		}
+		t := time.Date(day.Year(), day.Month(), day.Day(), 23, 59, 59, 0, time.Local)
+		return &t, false, nil
+	}
+
+	day := now
+	if datePart != "" {
+		if day, err = parseDueDay(datePart, now); err != nil {
+			return nil, false, err
+		}
+	}
+	t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+	return &t, true, nil
+}
+
+// splitDueTime separates a trailing time of day from the date before it
+func splitDueTime(s string) (datePart string, hour, minute int, ok bool) {
+	m := dueTime.FindStringSubmatch(s)
+	if m == nil || (m[3] == "" && m[4] == "") {
+		// A bare number is not a time
+		return "", 0, 0, false
+	}
+
+	hour, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		minute, _ = strconv.Atoi(m[3])
+	}
+	switch m[4] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return "", 0, 0, false
+		}
+		hour %= 12
+		if m[4] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return "", 0, 0, false
+	}
+	return m[1], hour, minute, true
+}
+
+// parseDueDay resolves the date part of a due date relative to now, at the
+// start of the day in now's location
+func parseDueDay(s string, now time.Time) (time.Time, error) {
+	today := startOfDay(now)
+	expr := strings.Join(strings.Fields(strings.ToLower(s)), " ")
+
+	switch expr {
+	case "today":
+		return today, nil
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	case "next-week", "nextweek", "next week":
+		return today.AddDate(0, 0, 7), nil
+	case "next month":
+		return addDateUnit(today, 1, "m"), nil
+	case "next year":
+		return addDateUnit(today, 1, "y"), nil
+	case "eow", "end of week", "end of the week":
+		return weekStart(today).AddDate(0, 0, 6), nil
+	case "eom", "end of month", "end of the month":
+		return time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, today.Location()), nil
+	case "eoq", "end of quarter", "end of the quarter":
+		return quarterEnd(today.Year(), (int(today.Month())+2)/3, today.Location()), nil
+	case "eoy", "end of year", "end of the year":
+		return time.Date(today.Year(), time.December, 31, 0, 0, 0, 0, today.Location()), nil
+	}
+
+	if day, ok := weekdays[expr]; ok {
+		return today.AddDate(0, 0, (int(day)-int(today.Weekday())+7)%7), nil
+	}
+	if name, ok := strings.CutPrefix(expr, "next "); ok {
+		if day, ok := weekdays[name]; ok {
+			// Weeks start on Monday, so Sunday is the last day of the week
+			return weekStart(today).AddDate(0, 0, 7+(int(day)+6)%7), nil
+		}
+	}
+
+	if m := relativeDate.FindStringSubmatch(expr); m != nil {
+		n, _ := strconv.Atoi(m[2])
+		if m[1] == "-" {
+			n = -n
+		}
+		return addDateUnit(today, n, m[3]), nil
+	}
+
+	if m := inDate.FindStringSubmatch(expr); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			n = 1 // "in a week"
+		}
+		unit := strings.TrimSuffix(m[2], "s")
+		switch unit {
+		case "day", "week", "month", "year":
+			return addDateUnit(today, n, unit[:1]), nil
+		case "":
+			return time.Time{}, Errorf(ErrInvalid, "missing unit in %q (use days, weeks, months or years)", s)
+		}
+		return time.Time{}, Errorf(ErrInvalid, "unknown unit %q in %q (use days, weeks, months or years)", m[2], s)
+	}
+
+	if m := isoWeekDate.FindStringSubmatch(expr); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		// January 4th is always in week 1
+		jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, today.Location())
+		sunday := weekStart(jan4).AddDate(0, 0, 7*(week-1)+6)
+		if y, w := sunday.ISOWeek(); week < 1 || y != year || w != week {
+			return time.Time{}, Errorf(ErrInvalid, "%d has no week %d", year, week)
+		}
+		return sunday, nil
+	}
+
+	if m := quarterDate.FindStringSubmatch(expr); m != nil {
+		quarter, _ := strconv.Atoi(m[2])
+		if quarter < 1 || quarter > 4 {
+			return time.Time{}, Errorf(ErrInvalid, "invalid quarter %q (use q1 to q4)", s)
+		}
+		if m[1] != "" {
+			year, _ := strconv.Atoi(m[1])
+			return quarterEnd(year, quarter, today.Location()), nil
+		}
+		end := quarterEnd(today.Year(), quarter, today.Location())
+		if end.Before(today) {
+			end = quarterEnd(today.Year()+1, quarter, today.Location())
+		}
+		return end, nil
+	}
+
+	for _, layout := range dueDateFormats {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	if numericDate.MatchString(expr) {
+		// Shaped like a date but not one, e.g. 2026-02-30
+		return time.Time{}, Errorf(ErrInvalid, "invalid date %q: no such day (use YYYY-MM-DD)", s)
+	}
+	return time.Time{}, Errorf(ErrInvalid, "unknown date %q (use e.g. 2026-02-14, today, friday, next friday, in 3 days, +2w, eom, q2 or 2026-W14)", s)
+}
+
+// addDateUnit adds n days (d), weeks (w), months (m) or years (y) to t.
+// Months and years end on the last day of a shorter target month.
+func addDateUnit(t time.Time, n int, unit string) time.Time {
+	switch unit {
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "m":
+		return model.AddMonths(t, n)
+	default:
+		return model.AddMonths(t, 12*n)
+	}
+}
+
+// weekStart returns the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// quarterEnd returns the last day of a quarter (1-4)
+func quarterEnd(year, quarter int, loc *time.Location) time.Time {
+	return time.Date(year, time.Month(3*quarter+1), 0, 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDueDay(t *testing.T) {
+	// A Saturday at the end of a 31-day month
+	now := time.Date(2026, time.January, 31, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		now  time.Time
+		want string
+	}{
+		{"today", now, "2026-01-31"},
+		{"Tomorrow", now, "2026-02-01"},
+		{"yesterday", now, "2026-01-30"},
+		{"next week", now, "2026-02-07"},
+		{"next month", now, "2026-02-28"},
+		{"next year", now, "2027-01-31"},
+
+		// Weekdays: the next one, today included
+		{"sat", now, "2026-01-31"},
+		{"friday", now, "2026-02-06"},
+		{"sun", now, "2026-02-01"},
+		{"next monday", now, "2026-02-02"},
+		{"next friday", now, "2026-02-06"},
+		{"next sunday", now, "2026-02-08"},
+
+		// Offsets, clamped at the end of shorter months
+		{"+3d", now, "2026-02-03"},
+		{"-1w", now, "2026-01-24"},
+		{"+2w", now, "2026-02-14"},
+		{"+1m", now, "2026-02-28"},
+		{"+2m", now, "2026-03-31"},
+		{"-2m", now, "2025-11-30"},
+		{"+1y", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC), "2029-02-28"},
+		{"+4y", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC), "2032-02-29"},
+
+		{"in 3 days", now, "2026-02-03"},
+		{"in a week", now, "2026-02-07"},
+		{"in 2 weeks", now, "2026-02-14"},
+		{"in 1 month", now, "2026-02-28"},
+		{"in  an  year", now, "2027-01-31"},
+
+		// Ends of periods
+		{"eow", now, "2026-02-01"},
+		{"end of the week", now, "2026-02-01"},
+		{"eom", now, "2026-01-31"},
+		{"end of month", time.Date(2028, time.February, 3, 0, 0, 0, 0, time.UTC), "2028-02-29"},
+		{"eoq", now, "2026-03-31"},
+		{"eoy", now, "2026-12-31"},
+
+		// Quarters: without a year the next one to end
+		{"q1", now, "2026-03-31"},
+		{"Q4", now, "2026-12-31"},
+		{"q1", time.Date(2026, time.May, 1, 0, 0, 0, 0, time.UTC), "2027-03-31"},
+		{"2025-q2", now, "2025-06-30"},
+		{"2027q3", now, "2027-09-30"},
+
+		// ISO weeks end on Sunday
+		{"2026-W01", now, "2026-01-04"},
+		{"2026-w14", now, "2026-04-05"},
+		{"2026W53", now, "2027-01-03"},
+		{"2021-W01", now, "2021-01-10"},
+
+		// Calendar dates
+		{"2026-02-14", now, "2026-02-14"},
+		{"2026/02/14", now, "2026-02-14"},
+		{"02/14/2026", now, "2026-02-14"},
+		{"Feb 14, 2026", now, "2026-02-14"},
+		{"February 14, 2026", now, "2026-02-14"},
+		{"2028-02-29", now, "2028-02-29"},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDueDay(tt.expr, tt.now)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if s := got.Format("2006-01-02"); s != tt.want {
+			t.Errorf("%q from %s: got %s, want %s", tt.expr, tt.now.Format("2006-01-02"), s, tt.want)
+		}
+		if got.Hour() != 0 || got.Minute() != 0 || got.Location() != tt.now.Location() {
+			t.Errorf("%q: got %s, want the start of the day in %s", tt.expr, got, tt.now.Location())
+		}
+	}
+}
+
+func TestParseDueDayErrors(t *testing.T) {
+	now := time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"2026-02-30", "no such day"},
+		{"2027-02-29", "no such day"},
+		{"2026/13/01", "no such day"},
+		{"in 3", "missing unit"},
+		{"in 3 fortnights", "unknown unit"},
+		{"q5", "invalid quarter"},
+		{"2026-q0", "invalid quarter"},
+		{"2025-W53", "2025 has no week 53"},
+		{"2026-W00", "2026 has no week 0"},
+		{"blursday", "unknown date"},
+		{"next blursday", "unknown date"},
+		{"+3x", "unknown date"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseDueDay(tt.expr, now)
+		if err == nil {
+			t.Errorf("%q: expected an error", tt.expr)
+			continue
+		}
+		if !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: got %v, want ErrInvalid", tt.expr, err)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: got %q, want it to mention %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestParseDueTime(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    string
+		hasTime bool
+	}{
+		{"2026-03-01", "2026-03-01 23:59:59", false},
+		{"2026-03-01 14:30", "2026-03-01 14:30:00", true},
+		{"2026-03-01T14:30", "2026-03-01 14:30:00", true},
+		{"2026-03-01 at 9am", "2026-03-01 09:00:00", true},
+		{"2026-03-01 5:15 pm", "2026-03-01 17:15:00", true},
+		{"2026-03-01 12am", "2026-03-01 00:00:00", true},
+		{"Mar 1, 2026 12pm", "2026-03-01 12:00:00", true},
+	}
+
+	for _, tt := range tests {
+		due, hasTime, err := ParseDue(tt.expr)
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if got := due.Format("2006-01-02 15:04:05"); got != tt.want || hasTime != tt.hasTime {
+			t.Errorf("%q: got %s (time %v), want %s (time %v)", tt.expr, got, hasTime, tt.want, tt.hasTime)
+		}
+	}
+
+	for _, expr := range []string{"2026-03-01 25:00", "2026-03-01 13pm", "2026-03-01 9:75"} {
+		if _, _, err := ParseDue(expr); err == nil {
+			t.Errorf("%q: expected an error", expr)
+		}
+	}
+}
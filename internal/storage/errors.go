@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+
+	"todo_cli/internal/model"
+)
+
+// Kinds of errors callers can tell apart with errors.Is
+var (
+	ErrNotFound = errors.New("not found") // the todo, project or view doesn't exist
+	ErrConflict = errors.New("conflict")  // the change clashes with the current state
+	ErrInvalid  = errors.New("invalid")   // the input is malformed or not allowed
+)
+
+// kindError is an error with its own message that also matches its kind
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// Errorf formats an error like fmt.Errorf that also matches kind (ErrNotFound,
+// ErrConflict or ErrInvalid) with errors.Is
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// ConflictError is returned by Update when the todo was changed since it was
+// loaded. It matches ErrConflict and carries the current state, so the caller
+// can reload or retry the write on top of it.
+type ConflictError struct {
+	Current *model.Todo
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("todo #%d was changed by someone else (now at version %d); reload and try again", e.Current.ID, e.Current.Version)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// isUniqueViolation reports whether err is SQLite refusing a duplicate value
+// in a UNIQUE column
+func isUniqueViolation(err error) bool {
+	var serr sqlite3.Error
+	return errors.As(err, &serr) && serr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
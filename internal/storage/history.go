@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// EventOp is the kind of change recorded in the history
+type EventOp string
+
+const (
+	EventCreate  EventOp = "create"
+	EventUpdate  EventOp = "update"
+	EventDelete  EventOp = "delete"
+	EventTrash   EventOp = "trash"
+	EventRestore EventOp = "restore"
+)
+
+// Event is a change to one field of a todo
+type Event struct {
+	ID       int64
+	TodoID   int64
+	Op       EventOp
+	Field    string
+	OldValue string
+	NewValue string
+	Actor    string
+	At       time.Time
+}
+
+// Verb describes the operation in the past tense
+func (e Event) Verb() string {
+	switch e.Op {
+	case EventCreate:
+		return "created"
+	case EventDelete:
+		return "deleted"
+	case EventTrash:
+		return "trashed"
+	case EventRestore:
+		return "restored"
+	default:
+		return "updated"
+	}
+}
+
+// Change renders the field change as "field: old → new"
+func (e Event) Change() string {
+	value := func(v string) string {
+		if v == "" {
+			return "(none)"
+		}
+		return strconv.Quote(v)
+	}
+
+	switch e.Op {
+	case EventCreate:
+		return fmt.Sprintf("%s: %s", e.Field, value(e.NewValue))
+	case EventDelete:
+		return fmt.Sprintf("%s: %s", e.Field, value(e.OldValue))
+	default:
+		return fmt.Sprintf("%s: %s → %s", e.Field, value(e.OldValue), value(e.NewValue))
+	}
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// eventActor is recorded as the author of every change
+var eventActor = currentUser()
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// auditField is a tracked todo attribute rendered as text
+type auditField struct {
+	name  string
+	value string
+}
+
+// auditFields lists the attributes whose changes are recorded. UpdatedAt is
+// left out: it changes with every write.
+func auditFields(t *model.Todo) []auditField {
+	if t == nil {
+		t = &model.Todo{}
+	}
+
+	fields := []auditField{
+		{"title", t.Title},
+		{"description", t.Description},
+		{"tags", strings.Join(t.Tags, " ")},
+		{"due", formatEventTime(t.DueDate)},
+		{"priority", ""},
+		{"completed", ""},
+		{"recurrence", t.Recurrence},
+		{"parent", ""},
+		{"project", t.Project},
+		{"deleted", formatEventTime(t.DeletedAt)},
+		{"archived", formatEventTime(t.ArchivedAt)},
+	}
+	if t.Priority > 0 {
+		fields[4].value = strconv.Itoa(t.Priority)
+	}
+	if t.Completed {
+		fields[5].value = formatEventTime(t.CompletedAt)
+		if fields[5].value == "" {
+			fields[5].value = "yes"
+		}
+	}
+	if t.ParentID != nil {
+		fields[7].value = fmt.Sprintf("#%d", *t.ParentID)
+	}
+	return fields
+}
+
+func formatEventTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// recordChanges logs every field that differs between before and after.
+// before is nil for creates and after is nil for deletes.
+func recordChanges(q dbtx, op EventOp, todoID int64, before, after *model.Todo) error {
+	old, cur := auditFields(before), auditFields(after)
+	now := time.Now().UTC()
+
+	for i := range old {
+		if old[i].value == cur[i].value {
+			continue
+		}
+		if err := recordEvent(q, Event{
+			TodoID:   todoID,
+			Op:       op,
+			Field:    old[i].name,
+			OldValue: old[i].value,
+			NewValue: cur[i].value,
+			At:       now,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func recordEvent(q dbtx, e Event) error {
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	_, err := q.Exec(`
+		INSERT INTO todo_events (todo_id, op, field, old_value, new_value, actor, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, e.TodoID, e.Op, e.Field, e.OldValue, e.NewValue, eventActor, e.At)
+	if err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+// History returns the changes made to a todo, oldest first
+func (s *SQLiteStorage) History(todoID int64) ([]Event, error) {
+	return s.queryEvents("WHERE todo_id = ? ORDER BY created_at, id", todoID)
+}
+
+// Events returns the changes made to any todo since the given time, oldest first
+func (s *SQLiteStorage) Events(since time.Time) ([]Event, error) {
+	return s.queryEvents("WHERE created_at >= ? ORDER BY created_at, id", since.UTC())
+}
+
+func (s *SQLiteStorage) queryEvents(where string, args ...interface{}) ([]Event, error) {
+	rows, err := s.conn().Query(
+		"SELECT id, todo_id, op, field, old_value, new_value, actor, created_at FROM todo_events "+where,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.TodoID, &e.Op, &e.Field, &e.OldValue, &e.NewValue, &e.Actor, &e.At); err != nil {
+			return nil, fmt.Errorf("failed to scan history: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history: %w", err)
+	}
+
+	return events, nil
+}
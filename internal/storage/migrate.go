@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when a database was migrated by a newer binary
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of todo supports")
+
+// migration is one ordered, versioned schema change
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// MigrationStatus describes a migration and whether it has been applied
+type MigrationStatus struct {
+	Version     int
+	Description string
+	AppliedAt   *time.Time
+	Unknown     bool // applied by a newer binary
+}
+
+// Applied returns true if the migration has been applied to the database
+func (m MigrationStatus) Applied() bool {
+	return m.AppliedAt != nil
+}
+
+// LatestSchemaVersion returns the schema version this binary migrates to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// Migrator inspects and applies schema migrations
+type Migrator struct {
+	db *sql.DB
+}
+
+// NewMigrator opens the database at dbPath without migrating it
+func NewMigrator(dbPath string) (*Migrator, error) {
+	db, err := openDB(dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db}, nil
+}
+
+// Close closes the database connection
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Status lists every known migration, plus any unknown versions recorded by a newer binary
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := appliedMigrations(m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, mig := range migrations {
+		st := MigrationStatus{Version: mig.version, Description: mig.description}
+		if at, ok := applied[mig.version]; ok {
+			st.AppliedAt = &at
+			delete(applied, mig.version)
+		}
+		statuses = append(statuses, st)
+	}
+
+	for version, at := range applied {
+		statuses = append(statuses, MigrationStatus{
+			Version:     version,
+			Description: "unknown (applied by a newer version of todo)",
+			AppliedAt:   &at,
+			Unknown:     true,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending() ([]MigrationStatus, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []MigrationStatus
+	for _, st := range statuses {
+		if st.Unknown {
+			return nil, fmt.Errorf("%w (found version %d, latest known is %d)", ErrSchemaTooNew, st.Version, LatestSchemaVersion())
+		}
+		if !st.Applied() {
+			pending = append(pending, st)
+		}
+	}
+
+	return pending, nil
+}
+
+// Migrate applies all pending migrations and returns them
+func (m *Migrator) Migrate() ([]MigrationStatus, error) {
+	pending, err := m.Pending()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrate(m.db); err != nil {
+		return nil, err
+	}
+
+	return pending, nil
+}
+
+// migrate applies pending migrations in order, each in its own transaction
+func migrate(db *sql.DB) error {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedMigrations(db)
+	if err != nil {
+		return err
+	}
+
+	for version := range applied {
+		if version > LatestSchemaVersion() {
+			return fmt.Errorf("%w (found version %d, latest known is %d)", ErrSchemaTooNew, version, LatestSchemaVersion())
+		}
+	}
+
+	for _, mig := range migrations {
+		if _, ok := applied[mig.version]; ok {
+			continue
+		}
+		if err := applyMigration(db, mig); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", mig.version, mig.description, err)
+		}
+	}
+
+	return nil
+}
+
+func applyMigration(db *sql.DB, mig migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := mig.up(tx); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)",
+		mig.version, mig.description, time.Now().UTC(),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func appliedMigrations(db *sql.DB) (map[int]time.Time, error) {
+	applied := make(map[int]time.Time)
+
+	var exists int
+	if err := db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if exists == 0 {
+		return applied, nil
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = at
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,265 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// baselineSchema is the schema databases had before migrations were
+// tracked: tags as a JSON column and due dates with their local offset
+const baselineSchema = `
+CREATE TABLE IF NOT EXISTS todos (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title TEXT NOT NULL,
+    description TEXT DEFAULT '',
+    tags TEXT DEFAULT '[]',
+    due_date DATETIME,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL,
+    completed_at DATETIME,
+    completed INTEGER DEFAULT 0,
+    priority INTEGER DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos(completed);
+CREATE INDEX IF NOT EXISTS idx_todos_due_date ON todos(due_date);
+CREATE INDEX IF NOT EXISTS idx_todos_priority ON todos(priority);
+`
+
+// newBaselineDB creates a database with the baseline schema and rows as the
+// baseline binary wrote them
+func newBaselineDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "todos.db")
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(baselineSchema); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+		INSERT INTO todos (id, title, description, tags, due_date, created_at, updated_at, completed_at, completed, priority) VALUES
+		(1, 'Tagged', 'two tags', '["work","urgent"]', '2026-03-01 23:59:59+02:00', '2026-01-01 10:00:00+00:00', '2026-01-02 10:00:00+00:00', NULL, 0, 1),
+		(2, 'Untagged', '', '[]', NULL, '2026-01-01 11:00:00+00:00', '2026-01-01 11:00:00+00:00', NULL, 0, 0),
+		(3, 'Done', '', '["home","home",""]', '2025-12-31 23:59:59-05:00', '2026-01-01 12:00:00+00:00', '2026-01-03 12:00:00+00:00', '2026-01-03 12:00:00+00:00', 1, 3),
+		(4, 'Broken tags', '', 'not json', NULL, '2026-01-01 13:00:00+00:00', '2026-01-01 13:00:00+00:00', NULL, 0, 0),
+		(5, 'Null tags', '', NULL, NULL, '2026-01-01 14:00:00+00:00', '2026-01-01 14:00:00+00:00', NULL, 0, 0)
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrateBaseline(t *testing.T) {
+	path := newBaselineDB(t)
+
+	s, err := NewSQLiteStorageWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The tags column is gone, its contents moved to todo_tags in order
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('todos') WHERE name = 'tags'").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Error("todos.tags was not dropped")
+	}
+
+	wantTags := map[int64][]string{
+		1: {"work", "urgent"},
+		2: nil,
+		3: {"home"}, // duplicates and empty tags dropped
+		4: nil,      // invalid JSON skipped rather than failing the migration
+		5: nil,
+	}
+	for id, want := range wantTags {
+		todo, err := s.GetByID(id)
+		if err != nil {
+			t.Fatalf("#%d: %v", id, err)
+		}
+		if !slices.Equal(todo.Tags, want) {
+			t.Errorf("#%d: got tags %q, want %q", id, todo.Tags, want)
+		}
+	}
+
+	// Everything else survives the table rebuild
+	todo, err := s.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if todo.Title != "Tagged" || todo.Description != "two tags" || todo.Priority != 1 || todo.Version != 1 {
+		t.Errorf("#1 changed: %+v", todo)
+	}
+	done, err := s.GetByID(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done.Completed || done.CompletedAt == nil || done.Priority != 3 {
+		t.Errorf("#3 lost its completion: %+v", done)
+	}
+
+	// Due dates are rewritten in UTC, as the same instant, without a time
+	wantDue := map[int64]string{
+		1: "2026-03-01 21:59:59",
+		3: "2026-01-01 04:59:59",
+	}
+	for id, want := range wantDue {
+		var raw string
+		if err := s.db.QueryRow("SELECT due_date FROM todos WHERE id = ?", id).Scan(&raw); err != nil {
+			t.Fatal(err)
+		}
+		todo, err := s.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := todo.DueDate.UTC().Format("2006-01-02 15:04:05"); got != want {
+			t.Errorf("#%d: got due %s, want %s UTC", id, got, want)
+		}
+		if !strings.HasSuffix(raw, "+00:00") && !strings.HasSuffix(raw, "Z") {
+			t.Errorf("#%d: due date %q is not stored in UTC", id, raw)
+		}
+		if todo.DueHasTime {
+			t.Errorf("#%d: migrated due date has a time", id)
+		}
+	}
+
+	// The migrated database works like a new one
+	if _, err := s.List(Filter{Tags: []string{"work"}}); err != nil {
+		t.Fatal(err)
+	}
+	todo.Tags = []string{"work", "later"}
+	if err := s.Update(todo); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	path := newBaselineDB(t)
+
+	for range 2 {
+		s, err := NewSQLiteStorageWithPath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+	}
+
+	m, err := NewMigrator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("got %d pending migrations after migrating", len(pending))
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != len(migrations) {
+		t.Fatalf("got %d statuses, want %d", len(statuses), len(migrations))
+	}
+	for _, st := range statuses {
+		if !st.Applied() || st.Unknown {
+			t.Errorf("migration %d: applied %v, unknown %v", st.Version, st.Applied(), st.Unknown)
+		}
+	}
+
+	// Due dates are rewritten once, not shifted again
+	s, err := NewSQLiteStorageWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	todo, err := s.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := todo.DueDate.UTC().Format(time.DateTime); got != "2026-03-01 21:59:59" {
+		t.Errorf("got due %s after migrating twice", got)
+	}
+}
+
+func TestMigratorPending(t *testing.T) {
+	m, err := NewMigrator(newBaselineDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	pending, err := m.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != len(migrations) {
+		t.Fatalf("got %d pending migrations, want %d", len(pending), len(migrations))
+	}
+
+	applied, err := m.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) || applied[len(applied)-1].Version != LatestSchemaVersion() {
+		t.Errorf("got %d applied migrations, want %d", len(applied), len(migrations))
+	}
+}
+
+func TestSchemaTooNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+	s, err := NewSQLiteStorageWithPath(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As a newer binary would record it
+	if _, err := s.db.Exec(
+		"INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, 'from the future', ?)",
+		LatestSchemaVersion()+1, time.Now().UTC(),
+	); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	if _, err := NewSQLiteStorageWithPath(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("opening: got %v, want ErrSchemaTooNew", err)
+	}
+
+	m, err := NewMigrator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	if _, err := m.Pending(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("pending: got %v, want ErrSchemaTooNew", err)
+	}
+	if _, err := m.Migrate(); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("migrate: got %v, want ErrSchemaTooNew", err)
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := statuses[len(statuses)-1]; !last.Unknown || last.Version != LatestSchemaVersion()+1 {
+		t.Errorf("got last status %+v, want the unknown version", last)
+	}
+}
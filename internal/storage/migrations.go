@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// migrations is the ordered list of schema changes. Append new entries at
+// the end and never edit or reorder released ones: a database records the
+// versions it has applied.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create todos table",
+		up: execSQL(`
+			CREATE TABLE IF NOT EXISTS todos (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				title TEXT NOT NULL,
+				description TEXT DEFAULT '',
+				tags TEXT DEFAULT '[]',
+				due_date DATETIME,
+				created_at DATETIME NOT NULL,
+				updated_at DATETIME NOT NULL,
+				completed_at DATETIME,
+				completed INTEGER DEFAULT 0,
+				priority INTEGER DEFAULT 0
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos(completed);
+			CREATE INDEX IF NOT EXISTS idx_todos_due_date ON todos(due_date);
+			CREATE INDEX IF NOT EXISTS idx_todos_priority ON todos(priority);
+		`),
+	},
+	{
+		version:     2,
+		description: "add recurrence rules",
+		up:          addColumn("todos", "recurrence", "TEXT DEFAULT ''"),
+	},
+	{
+		version:     3,
+		description: "add subtask hierarchy",
+		up: steps(
+			addColumn("todos", "parent_id", "INTEGER REFERENCES todos(id) ON DELETE SET NULL"),
+			execSQL("CREATE INDEX IF NOT EXISTS idx_todos_parent_id ON todos(parent_id)"),
+		),
+	},
+	{
+		version:     4,
+		description: "add projects",
+		up: steps(
+			execSQL(`
+				CREATE TABLE IF NOT EXISTS projects (
+					id INTEGER PRIMARY KEY AUTOINCREMENT,
+					name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+					archived INTEGER DEFAULT 0,
+					created_at DATETIME NOT NULL
+				)
+			`),
+			addColumn("todos", "project_id", "INTEGER REFERENCES projects(id) ON DELETE SET NULL"),
+			execSQL("CREATE INDEX IF NOT EXISTS idx_todos_project_id ON todos(project_id)"),
+		),
+	},
+	{
+		version:     5,
+		description: "add task dependencies",
+		up: execSQL(`
+			CREATE TABLE IF NOT EXISTS todo_dependencies (
+				todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+				blocked_by_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+				PRIMARY KEY (todo_id, blocked_by_id),
+				CHECK (todo_id != blocked_by_id)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_todo_dependencies_blocked_by ON todo_dependencies(blocked_by_id);
+		`),
+	},
+	{
+		version:     6,
+		description: "move tags into the todo_tags table",
+		up: execSQL(`
+			CREATE TABLE todo_tags (
+				todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+				tag TEXT NOT NULL,
+				position INTEGER NOT NULL DEFAULT 0,
+				PRIMARY KEY (todo_id, tag)
+			);
+
+			CREATE INDEX idx_todo_tags_tag ON todo_tags(tag, todo_id);
+
+			INSERT OR IGNORE INTO todo_tags (todo_id, tag, position)
+			SELECT todos.id, j.value, j.key
+			FROM todos, json_each(todos.tags) j
+			WHERE json_valid(todos.tags) AND j.type = 'text' AND j.value != '';
+
+			ALTER TABLE todos DROP COLUMN tags;
+		`),
+	},
+	{
+		version:     7,
+		description: "add saved views",
+		up: execSQL(`
+			CREATE TABLE views (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+				filter TEXT NOT NULL,
+				created_at DATETIME NOT NULL
+			);
+		`),
+	},
+	{
+		version:     8,
+		description: "add change history",
+		up: execSQL(`
+			CREATE TABLE todo_events (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				todo_id INTEGER NOT NULL,
+				op TEXT NOT NULL,
+				field TEXT NOT NULL,
+				old_value TEXT NOT NULL DEFAULT '',
+				new_value TEXT NOT NULL DEFAULT '',
+				actor TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL
+			);
+
+			CREATE INDEX idx_todo_events_todo ON todo_events(todo_id, created_at);
+			CREATE INDEX idx_todo_events_created ON todo_events(created_at);
+		`),
+	},
+	{
+		version:     9,
+		description: "add undo log",
+		up: execSQL(`
+			CREATE TABLE undo_groups (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				description TEXT NOT NULL,
+				undone INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME NOT NULL
+			);
+
+			CREATE TABLE undo_entries (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				group_id INTEGER NOT NULL REFERENCES undo_groups(id) ON DELETE CASCADE,
+				todo_id INTEGER NOT NULL,
+				before TEXT,
+				after TEXT
+			);
+
+			CREATE INDEX idx_undo_entries_group ON undo_entries(group_id);
+		`),
+	},
+	{
+		version:     10,
+		description: "add soft delete",
+		up: steps(
+			addColumn("todos", "deleted_at", "DATETIME"),
+			execSQL("CREATE INDEX IF NOT EXISTS idx_todos_deleted_at ON todos(deleted_at)"),
+		),
+	},
+	{
+		version:     11,
+		description: "add archiving and settings",
+		up: steps(
+			addColumn("todos", "archived_at", "DATETIME"),
+			execSQL("CREATE INDEX IF NOT EXISTS idx_todos_archived_at ON todos(archived_at)"),
+			execSQL(`
+				CREATE TABLE IF NOT EXISTS settings (
+					key TEXT PRIMARY KEY,
+					value TEXT NOT NULL
+				)
+			`),
+		),
+	},
+	{
+		version:     12,
+		description: "add todo versions for optimistic locking",
+		up:          addColumn("todos", "version", "INTEGER NOT NULL DEFAULT 1"),
+	},
+	{
+		version:     13,
+		description: "store due dates in UTC and add due times",
+		up: steps(
+			addColumn("todos", "due_has_time", "INTEGER NOT NULL DEFAULT 0"),
+			dueDatesToUTC,
+		),
+	},
+}
+
+// dueDatesToUTC rewrites due dates, which used to be stored with the local
+// zone offset, in UTC like all other timestamps so they compare correctly
+func dueDatesToUTC(tx *sql.Tx) error {
+	rows, err := tx.Query("SELECT id, due_date FROM todos WHERE due_date IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	due := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var t time.Time
+		if err := rows.Scan(&id, &t); err != nil {
+			rows.Close()
+			return err
+		}
+		due[id] = t.UTC()
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, t := range due {
+		if _, err := tx.Exec("UPDATE todos SET due_date = ? WHERE id = ?", t, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func execSQL(query string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		_, err := tx.Exec(query)
+		return err
+	}
+}
+
+func steps(fns ...func(tx *sql.Tx) error) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, fn := range fns {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumn adds a column unless it already exists. Databases created before
+// migrations were tracked may already have it.
+func addColumn(table, column, definition string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		exists, err := columnExists(tx, table, column)
+		if err != nil || exists {
+			return err
+		}
+
+		_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+		return err
+	}
+}
+
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	var n int
+	err := tx.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&n)
+	return n > 0, err
+}
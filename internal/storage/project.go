@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// CreateProject inserts a new project
+func (s *SQLiteStorage) CreateProject(project *model.Project) error {
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		return Errorf(ErrInvalid, "project name is required")
+	}
+	project.CreatedAt = time.Now().UTC()
+
+	result, err := s.conn().Exec(
+		"INSERT INTO projects (name, archived, created_at) VALUES (?, ?, ?)",
+		project.Name, boolToInt(project.Archived), project.CreatedAt,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return Errorf(ErrConflict, "project %q already exists", project.Name)
+		}
+		return fmt.Errorf("failed to insert project: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	project.ID = id
+	return nil
+}
+
+// GetProject retrieves a project by name (case-insensitive)
+func (s *SQLiteStorage) GetProject(name string) (*model.Project, error) {
+	row := s.conn().QueryRow("SELECT id, name, archived, created_at FROM projects WHERE name = ?", name)
+
+	project, err := scanProject(row)
+	if err == sql.ErrNoRows {
+		return nil, Errorf(ErrNotFound, "project %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return project, nil
+}
+
+// ListProjects returns projects ordered by name
+func (s *SQLiteStorage) ListProjects(includeArchived bool) ([]model.Project, error) {
+	query := "SELECT id, name, archived, created_at FROM projects"
+	if !includeArchived {
+		query += " WHERE archived = 0"
+	}
+	query += " ORDER BY name COLLATE NOCASE"
+
+	rows, err := s.conn().Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []model.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, *project)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// RenameProject changes a project's name
+func (s *SQLiteStorage) RenameProject(oldName, newName string) error {
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return Errorf(ErrInvalid, "project name is required")
+	}
+
+	result, err := s.conn().Exec("UPDATE projects SET name = ? WHERE name = ?", newName, oldName)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return Errorf(ErrConflict, "project %q already exists", newName)
+		}
+		return fmt.Errorf("failed to rename project: %w", err)
+	}
+
+	return expectRow(result, fmt.Sprintf("project %q not found", oldName))
+}
+
+// ArchiveProject archives or restores a project. Its todos are kept.
+func (s *SQLiteStorage) ArchiveProject(name string, archived bool) error {
+	result, err := s.conn().Exec("UPDATE projects SET archived = ? WHERE name = ?", boolToInt(archived), name)
+	if err != nil {
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+
+	return expectRow(result, fmt.Sprintf("project %q not found", name))
+}
+
+// ProjectStats returns open, overdue and completed counts for every active
+// project. Like the overdue filter, todos due earlier today aren't overdue.
+func (s *SQLiteStorage) ProjectStats() ([]ProjectStats, error) {
+	rows, err := s.conn().Query(`
+		SELECT p.id, p.name, p.archived, p.created_at,
+			COUNT(t.id) FILTER (WHERE t.completed = 0),
+			COUNT(t.id) FILTER (WHERE t.completed = 0 AND t.due_date < ?),
+			COUNT(t.id) FILTER (WHERE t.completed = 1)
+		FROM projects p
+		LEFT JOIN todos t ON t.project_id = p.id AND t.deleted_at IS NULL
+		WHERE p.archived = 0
+		GROUP BY p.id
+		ORDER BY p.name COLLATE NOCASE
+	`, startOfDay(time.Now()).UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query project stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []ProjectStats
+	for rows.Next() {
+		var st ProjectStats
+		var archived int
+		if err := rows.Scan(
+			&st.Project.ID, &st.Project.Name, &archived, &st.Project.CreatedAt,
+			&st.Open, &st.Overdue, &st.Completed,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan project stats: %w", err)
+		}
+		st.Project.Archived = archived == 1
+		stats = append(stats, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating project stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+func scanProject(row rowScanner) (*model.Project, error) {
+	var project model.Project
+	var archived int
+
+	if err := row.Scan(&project.ID, &project.Name, &archived, &project.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	project.Archived = archived == 1
+	return &project, nil
+}
+
+func expectRow(result sql.Result, notFound string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return Errorf(ErrNotFound, "%s", notFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+func TestProjectNameConflicts(t *testing.T) {
+	s := newTestStorage(t)
+	for _, name := range []string{"web", "mobile"} {
+		if err := s.CreateProject(&model.Project{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Names are unique regardless of case
+	if err := s.CreateProject(&model.Project{Name: "Web"}); !errors.Is(err, ErrConflict) {
+		t.Errorf("creating a duplicate: got %v, want a conflict", err)
+	}
+	if err := s.RenameProject("mobile", "WEB"); !errors.Is(err, ErrConflict) {
+		t.Errorf("renaming onto another project: got %v, want a conflict", err)
+	}
+	if err := s.RenameProject("mobile", "apps"); err != nil {
+		t.Errorf("renaming: %v", err)
+	}
+	if err := s.RenameProject("mobile", "ios"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("renaming a missing project: got %v, want not found", err)
+	}
+}
+
+func TestProjectStatsOverdue(t *testing.T) {
+	s := newTestStorage(t)
+	web := &model.Project{Name: "web"}
+	if err := s.CreateProject(web); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 1, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	for _, todo := range []*model.Todo{
+		{Title: "late", DueDate: &yesterday},
+		{Title: "due today", DueDate: &today},
+		{Title: "done late", DueDate: &yesterday, Completed: true},
+		{Title: "undated"},
+	} {
+		todo.ProjectID = &web.ID
+		mustCreate(t, s, todo)
+	}
+
+	stats, err := s.ProjectStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("got stats for %d projects, want 1", len(stats))
+	}
+	// A todo due earlier today isn't overdue, as with list --overdue
+	if st := stats[0]; st.Open != 3 || st.Overdue != 1 || st.Completed != 1 {
+		t.Errorf("got %d open, %d overdue, %d completed, want 3, 1, 1", st.Open, st.Overdue, st.Completed)
+	}
+}
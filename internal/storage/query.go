@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo_cli/internal/query"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// queryCompiler turns a filter expression into a parameterized WHERE condition
+type queryCompiler struct {
+	source string
+	fts    bool
+	now    time.Time
+	args   []interface{}
+}
+
+// compileQuery parses and compiles a filter expression
+func (s *SQLiteStorage) compileQuery(source string) (string, []interface{}, error) {
+	expr, err := query.Parse(source)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c := &queryCompiler{source: source, fts: s.fts, now: time.Now().In(time.Local)}
+	cond, err := c.compile(expr)
+	if err != nil {
+		return "", nil, err
+	}
+	return cond, c.args, nil
+}
+
+func (c *queryCompiler) compile(expr query.Expr) (string, error) {
+	switch e := expr.(type) {
+	case *query.Binary:
+		left, err := c.compile(e.Left)
+		if err != nil {
+			return "", err
+		}
+		right, err := c.compile(e.Right)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", left, strings.ToUpper(string(e.Op)), right), nil
+	case *query.Not:
+		x, err := c.compile(e.X)
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + x, nil
+	case *query.Text:
+		search := e.Value
+		if e.Phrase {
+			search = `"` + search + `"`
+		}
+		cond, args, err := searchCondition(search, c.fts)
+		if err != nil {
+			return "", query.Errorf(c.source, e.At, "%v", err)
+		}
+		c.args = append(c.args, args...)
+		return cond, nil
+	case *query.Cond:
+		return c.compileCond(e)
+	default:
+		return "", fmt.Errorf("unsupported filter expression %T", expr)
+	}
+}
+
+func (c *queryCompiler) compileCond(e *query.Cond) (string, error) {
+	op := e.Op
+	if op == query.OpMatch {
+		op = query.OpEq
+	}
+
+	switch e.Field {
+	case query.FieldID:
+		return c.compare("id", op, e.Value), nil
+
+	case query.FieldPriority:
+		if e.Value == query.None {
+			return c.negate(op, "priority = 0"), nil
+		}
+		cond := c.compare("priority", op, e.Value)
+		// No priority ranks below every level, so it never satisfies < or <=
+		if op == query.OpLt || op == query.OpLe {
+			cond = "(priority != 0 AND " + cond + ")"
+		}
+		return cond, nil
+
+	case query.FieldDue, query.FieldCreated, query.FieldUpdated, query.FieldCompleted:
+		return c.compileDate(e, op)
+
+	case query.FieldTag:
+		if e.Value == query.None {
+			return c.negate(op, "id NOT IN (SELECT todo_id FROM todo_tags)"), nil
+		}
+		tags := ParseTags(e.Value)
+		if len(tags) != 1 {
+			return "", query.Errorf(c.source, e.ValueAt, "tag expects a single tag, got %q", e.Value)
+		}
+		c.args = append(c.args, tags[0])
+		return c.negate(op, "id IN (SELECT todo_id FROM todo_tags WHERE tag = ?)"), nil
+
+	case query.FieldProject:
+		if e.Value == query.None {
+			return c.negate(op, "project_id IS NULL"), nil
+		}
+		c.args = append(c.args, e.Value)
+		return c.negate(op, "project_id IN (SELECT id FROM projects WHERE name = ?)"), nil
+
+	case query.FieldTitle, query.FieldDesc:
+		column := "title"
+		if e.Field == query.FieldDesc {
+			column = "description"
+		}
+		c.args = append(c.args, "%"+likeEscaper.Replace(e.Value)+"%")
+		return c.negate(op, column+` LIKE ? ESCAPE '\'`), nil
+
+	case query.FieldStatus:
+		var cond string
+		switch e.Value {
+		case "open":
+			cond = "completed = 0"
+		case "done":
+			cond = "completed = 1"
+		case "blocked":
+			cond = "(completed = 0 AND " + openBlockersCondition + ")"
+		case "ready":
+			cond = "(completed = 0 AND NOT " + openBlockersCondition + ")"
+		case "overdue":
+			c.args = append(c.args, startOfDay(c.now).UTC())
+			cond = "(completed = 0 AND due_date < ?)"
+		}
+		return c.negate(op, cond), nil
+	}
+
+	return "", query.Errorf(c.source, e.At, "unsupported field %s", e.Field)
+}
+
+// compileDate compares a timestamp column by whole days: due < +3d means
+// before the start of the day three days from now
+func (c *queryCompiler) compileDate(e *query.Cond, op query.Op) (string, error) {
+	column := map[query.Field]string{
+		query.FieldDue:       "due_date",
+		query.FieldCreated:   "created_at",
+		query.FieldUpdated:   "updated_at",
+		query.FieldCompleted: "completed_at",
+	}[e.Field]
+
+	if e.Value == query.None {
+		return c.negate(op, column+" IS NULL"), nil
+	}
+
+	day, err := parseDueDay(e.Value, c.now)
+	if err != nil {
+		return "", query.Errorf(c.source, e.ValueAt, "%v", err)
+	}
+	start, end := day, day.AddDate(0, 0, 1)
+
+	// Days start at midnight in time.Local; timestamps are stored in UTC
+	arg := func(t time.Time) interface{} {
+		return t.UTC()
+	}
+
+	switch op {
+	case query.OpEq:
+		c.args = append(c.args, arg(start), arg(end))
+		return fmt.Sprintf("(%s >= ? AND %s < ?)", column, column), nil
+	case query.OpNe:
+		c.args = append(c.args, arg(start), arg(end))
+		return fmt.Sprintf("(%s < ? OR %s >= ?)", column, column), nil
+	case query.OpLt:
+		c.args = append(c.args, arg(start))
+		return column + " < ?", nil
+	case query.OpLe:
+		c.args = append(c.args, arg(end))
+		return column + " < ?", nil
+	case query.OpGt:
+		c.args = append(c.args, arg(end))
+		return column + " >= ?", nil
+	default:
+		c.args = append(c.args, arg(start))
+		return column + " >= ?", nil
+	}
+}
+
+// compare renders column op ? for a validated numeric value
+func (c *queryCompiler) compare(column string, op query.Op, value string) string {
+	n, _ := strconv.Atoi(value)
+	c.args = append(c.args, n)
+	return fmt.Sprintf("%s %s ?", column, op)
+}
+
+// negate wraps cond in NOT for the != operator
+func (c *queryCompiler) negate(op query.Op, cond string) string {
+	if op == query.OpNe {
+		return "NOT (" + cond + ")"
+	}
+	return cond
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/query"
+)
+
+func TestQuery(t *testing.T) {
+	s := newTestStorage(t)
+
+	web := &model.Project{Name: "web"}
+	if err := s.CreateProject(web); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	earlierToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 1, 0, time.Local)
+	yesterday := earlierToday.AddDate(0, 0, -1)
+	nextWeek := earlierToday.AddDate(0, 0, 7)
+
+	// Titles are the names the cases below refer to
+	todos := map[string]*model.Todo{
+		"50% done":   {Priority: 1, Tags: []string{"#work"}, DueDate: &yesterday},
+		"500 things": {Priority: 2, Tags: []string{"#work", "#oncall"}, ProjectID: &web.ID},
+		"a_b":        {Priority: 3, DueDate: &earlierToday},
+		"axb":        {DueDate: &nextWeek, ProjectID: &web.ID},
+		`back\slash`: {Priority: 5, Tags: []string{"#home"}, Description: "under_score"},
+		"finished":   {Priority: 1, Completed: true, DueDate: &yesterday},
+	}
+	byID := make(map[int64]string)
+	for title, todo := range todos {
+		todo.Title = title
+		mustCreate(t, s, todo)
+		byID[todo.ID] = title
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		// LIKE wildcards in values match themselves
+		{"title:50%", []string{"50% done"}},
+		{"title:a_b", []string{"a_b"}},
+		{`title:\`, []string{`back\slash`}},
+		{"title!=a_b", []string{"50% done", "500 things", "axb", `back\slash`, "finished"}},
+		{"desc:_", []string{`back\slash`}},
+
+		// none
+		{"priority:none", []string{"axb"}},
+		{"priority != none", []string{"50% done", "500 things", "a_b", `back\slash`, "finished"}},
+		{"due:none", []string{"500 things", `back\slash`}},
+		{"tag:none", []string{"a_b", "axb", "finished"}},
+		{"project:none", []string{"50% done", "a_b", `back\slash`, "finished"}},
+		{"project = web", []string{"500 things", "axb"}},
+
+		// No priority ranks below every level
+		{"priority <= 2", []string{"50% done", "500 things", "finished"}},
+		{"priority > 3", []string{`back\slash`}},
+
+		// Precedence and negation
+		{"priority:1 or priority:2 tag:#oncall", []string{"50% done", "500 things", "finished"}},
+		{"(priority:1 or priority:2) tag:#oncall", []string{"500 things"}},
+		{"tag:#work not tag:#oncall", []string{"50% done"}},
+		{"not (tag:#work or tag:#home)", []string{"a_b", "axb", "finished"}},
+		{"!status:done priority:1", []string{"50% done"}},
+
+		// Overdue starts at the start of today, like list --overdue
+		{"status:overdue", []string{"50% done"}},
+		{"due < today", []string{"50% done", "finished"}},
+		{"due = today", []string{"a_b"}},
+		{"due >= +7d", []string{"axb"}},
+	}
+
+	for _, tt := range tests {
+		list, err := s.List(Filter{Query: tt.query})
+		if err != nil {
+			t.Errorf("%q: %v", tt.query, err)
+			continue
+		}
+		var got []string
+		for _, todo := range list {
+			got = append(got, byID[todo.ID])
+		}
+		slices.Sort(got)
+		slices.Sort(tt.want)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q:\n got %q\nwant %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryCompileErrors(t *testing.T) {
+	s := newTestStorage(t)
+
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"due < blursday", 7},
+		{"priority:1 and created > 2026-02-30", 26},
+		{`tag:"#a #b"`, 5},
+	}
+
+	for _, tt := range tests {
+		_, err := s.List(Filter{Query: tt.query})
+		var qerr *query.Error
+		if !errors.As(err, &qerr) {
+			t.Errorf("%q: got %v, want a query error", tt.query, err)
+			continue
+		}
+		if qerr.Pos != tt.pos {
+			t.Errorf("%q: got column %d, want %d", tt.query, qerr.Pos, tt.pos)
+		}
+	}
+}
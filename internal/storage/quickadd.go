@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"strconv"
+	"strings"
+
+	"todo_cli/internal/model"
+)
+
+// maxDueWords is the most words a due date at the end of a quick-add line
+// can span, e.g. "January 2, 2026 14:30"
+const maxDueWords = 5
+
+// dueConnectors are words left between a title and its due date
+var dueConnectors = map[string]bool{"at": true, "on": true, "by": true, "due": true}
+
+// QuickAddOptions limits what ParseQuickAdd reads from a line. Callers that
+// set a field some other way, such as a flag, keep that part in the title.
+type QuickAddOptions struct {
+	Raw          bool // read nothing: the line is the title as typed
+	KeepDue      bool
+	KeepPriority bool
+	KeepProject  bool
+}
+
+// ParseQuickAdd parses a todo written as one line, such as
+// "Ship release notes tomorrow 3pm #work !2 +website":
+//
+//   - #tag adds a tag
+//   - !N sets the priority (1=highest, 5=lowest)
+//   - +name puts the todo in a project; its name is returned in Project and
+//     left to the caller to resolve
+//   - the longest run of words at the end of the title that ParseDue
+//     accepts becomes the due date
+//
+// Everything else is the title.
+func ParseQuickAdd(line string, opts QuickAddOptions) (*model.Todo, error) {
+	if opts.Raw {
+		return &model.Todo{Title: line}, nil
+	}
+
+	todo := &model.Todo{}
+	var words []string
+
+	for _, word := range strings.Fields(line) {
+		switch {
+		case len(word) > 1 && word[0] == '#':
+			todo.Tags = append(todo.Tags, word)
+
+		case !opts.KeepPriority && len(word) > 1 && word[0] == '!':
+			priority, err := strconv.Atoi(word[1:])
+			if err != nil {
+				// Not a priority, e.g. "!!"
+				words = append(words, word)
+				continue
+			}
+			if priority < 1 || priority > 5 {
+				return nil, Errorf(ErrInvalid, "invalid priority %s (use !1 to !5)", word)
+			}
+			if todo.Priority != 0 {
+				return nil, Errorf(ErrInvalid, "more than one priority in %q", line)
+			}
+			todo.Priority = priority
+
+		case !opts.KeepProject && len(word) > 1 && word[0] == '+' && !relativeDate.MatchString(word):
+			if todo.Project != "" {
+				return nil, Errorf(ErrInvalid, "more than one project in %q", line)
+			}
+			todo.Project = word[1:]
+
+		default:
+			words = append(words, word)
+		}
+	}
+
+	// Keep at least one word of title
+	for n := min(maxDueWords, len(words)-1); n > 0 && !opts.KeepDue; n-- {
+		due, hasTime, err := ParseDue(strings.Join(words[len(words)-n:], " "))
+		if err != nil {
+			continue
+		}
+		todo.DueDate = due
+		todo.DueHasTime = hasTime
+		words = words[:len(words)-n]
+		if len(words) > 1 && dueConnectors[strings.ToLower(words[len(words)-1])] {
+			words = words[:len(words)-1]
+		}
+		break
+	}
+
+	todo.Title = strings.Join(words, " ")
+	if todo.Title == "" {
+		return nil, Errorf(ErrInvalid, "a todo needs a title")
+	}
+	return todo, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseQuickAdd(t *testing.T) {
+	tests := []struct {
+		line     string
+		opts     QuickAddOptions
+		title    string
+		tags     []string
+		priority int
+		project  string
+		due      string // as given to ParseDue, "" for none
+	}{
+		{line: "Buy groceries", title: "Buy groceries"},
+		{
+			line:  "Ship release notes tomorrow 3pm #work !2 +website",
+			title: "Ship release notes", tags: []string{"#work"}, priority: 2, project: "website", due: "tomorrow 3pm",
+		},
+
+		// #tag, anywhere in the line
+		{line: "#home Fix the #garden fence", title: "Fix the fence", tags: []string{"#home", "#garden"}},
+		{line: "Reply to # and #", title: "Reply to # and #"},
+
+		// !N, but only 1 to 5
+		{line: "!1 Pay taxes", title: "Pay taxes", priority: 1},
+		{line: "Wow!! !! nice", title: "Wow!! !! nice"},
+		{line: "Read !five", title: "Read !five"},
+
+		// +name, unless it is a relative date
+		{line: "Fix footer +website", title: "Fix footer", project: "website"},
+		{line: "Renew passport +3w", title: "Renew passport", due: "+3w"},
+		{line: "1 + 1", title: "1 + 1"},
+
+		// The longest run of up to 5 words at the end that is a due date
+		{line: "Call mom friday", title: "Call mom", due: "friday"},
+		{line: "Meet Ana next friday", title: "Meet Ana", due: "next friday"},
+		{line: "Water plants in 3 days", title: "Water plants", due: "in 3 days"},
+		{line: "Party on January 2, 2026 at 14:30", title: "Party", due: "January 2, 2026 at 14:30"},
+		{line: "Standup at 9am", title: "Standup", due: "9am"},
+		{line: "Leave friday early", title: "Leave friday early"},
+		// A due date needs a title besides it
+		{line: "tomorrow", title: "tomorrow"},
+		{line: "Due tomorrow", title: "Due", due: "tomorrow"},
+
+		// Fields given some other way stay in the title
+		{line: "review report friday", opts: QuickAddOptions{KeepDue: true}, title: "review report friday"},
+		{
+			line: "Deploy !1 +api #ops friday", opts: QuickAddOptions{KeepPriority: true, KeepProject: true},
+			title: "Deploy !1 +api", tags: []string{"#ops"}, due: "friday",
+		},
+		{line: "Fix #123 for good !2 tomorrow", opts: QuickAddOptions{Raw: true}, title: "Fix #123 for good !2 tomorrow"},
+		{line: "  spaced  ", opts: QuickAddOptions{Raw: true}, title: "  spaced  "},
+	}
+
+	for _, tt := range tests {
+		todo, err := ParseQuickAdd(tt.line, tt.opts)
+		if err != nil {
+			t.Errorf("%q: %v", tt.line, err)
+			continue
+		}
+		if todo.Title != tt.title {
+			t.Errorf("%q: got title %q, want %q", tt.line, todo.Title, tt.title)
+		}
+		if !slices.Equal(todo.Tags, tt.tags) {
+			t.Errorf("%q: got tags %q, want %q", tt.line, todo.Tags, tt.tags)
+		}
+		if todo.Priority != tt.priority {
+			t.Errorf("%q: got priority %d, want %d", tt.line, todo.Priority, tt.priority)
+		}
+		if todo.Project != tt.project {
+			t.Errorf("%q: got project %q, want %q", tt.line, todo.Project, tt.project)
+		}
+
+		want, hasTime, err := ParseDue(tt.due)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case want == nil && todo.DueDate != nil:
+			t.Errorf("%q: got due %s, want none", tt.line, todo.DueDate)
+		case want != nil && todo.DueDate == nil:
+			t.Errorf("%q: got no due date, want %s", tt.line, want)
+		case want != nil && (!todo.DueDate.Equal(*want) || todo.DueHasTime != hasTime):
+			t.Errorf("%q: got due %s (time %v), want %s (time %v)", tt.line, todo.DueDate, todo.DueHasTime, want, hasTime)
+		}
+	}
+}
+
+func TestParseQuickAddErrors(t *testing.T) {
+	for _, tt := range []struct {
+		line string
+		opts QuickAddOptions
+	}{
+		{line: "Sleep !7"},
+		{line: "Sleep !0"},
+		{line: "Sleep !1 !2"},
+		{line: "Move +home +work"},
+		{line: "#only #tags !3"},
+		{line: "   "},
+		// Only the parts that are read are checked
+		{line: "Sleep !9 +a +b", opts: QuickAddOptions{KeepPriority: true}},
+	} {
+		if todo, err := ParseQuickAdd(tt.line, tt.opts); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: got %+v, %v, want an invalid line", tt.line, todo, err)
+		}
+	}
+
+	opts := QuickAddOptions{KeepPriority: true, KeepProject: true}
+	if _, err := ParseQuickAdd("Sleep !9 +a +b", opts); err != nil {
+		t.Errorf("with the priority and project kept: %v", err)
+	}
+}
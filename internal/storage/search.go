@@ -0,0 +1,429 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"todo_cli/internal/model"
+)
+
+// Snippet highlight markers. Callers replace them with terminal styling.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+// SearchResult is a todo matched by full-text search
+type SearchResult struct {
+	Todo    model.Todo
+	Snippet string  // matching text with highlighted terms
+	Rank    float64 // lower is more relevant
+}
+
+// The search index is derived data, so it lives outside the migrations: it
+// needs SQLite built with FTS5 (go build -tags sqlite_fts5), and binaries
+// without it must still be able to write to the same database.
+var searchTriggers = []string{
+	"todos_fts_insert", "todos_fts_update", "todos_fts_delete",
+	"todo_tags_fts_insert", "todo_tags_fts_delete",
+}
+
+const searchIndexSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS todos_fts USING fts5(
+    title, description, tags,
+    tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS todos_fts_insert AFTER INSERT ON todos BEGIN
+    INSERT INTO todos_fts (rowid, title, description, tags) VALUES (new.id, new.title, new.description, '');
+END;
+
+CREATE TRIGGER IF NOT EXISTS todos_fts_update AFTER UPDATE OF title, description ON todos BEGIN
+    UPDATE todos_fts SET title = new.title, description = new.description WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS todos_fts_delete AFTER DELETE ON todos BEGIN
+    DELETE FROM todos_fts WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS todo_tags_fts_insert AFTER INSERT ON todo_tags BEGIN
+    UPDATE todos_fts SET tags = (SELECT group_concat(tag, ' ') FROM todo_tags WHERE todo_id = new.todo_id)
+    WHERE rowid = new.todo_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS todo_tags_fts_delete AFTER DELETE ON todo_tags BEGIN
+    UPDATE todos_fts SET tags = COALESCE((SELECT group_concat(tag, ' ') FROM todo_tags WHERE todo_id = old.todo_id), '')
+    WHERE rowid = old.todo_id;
+END;
+`
+
+// initSearchIndex sets up the FTS5 index when SQLite supports it, rebuilding
+// it if another binary left it stale. Without FTS5 the triggers are dropped
+// so that writes don't fail on the unknown module.
+func initSearchIndex(db *sql.DB) (bool, error) {
+	var available bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&available); err != nil {
+		return false, fmt.Errorf("failed to detect FTS5 support: %w", err)
+	}
+
+	if !available {
+		for _, name := range searchTriggers {
+			if _, err := db.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+				return false, fmt.Errorf("failed to drop search trigger: %w", err)
+			}
+		}
+		return false, nil
+	}
+
+	var triggers int
+	if err := db.QueryRow(
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN ("+placeholders(len(searchTriggers))+")",
+		stringArgs(searchTriggers)...,
+	).Scan(&triggers); err != nil {
+		return false, fmt.Errorf("failed to inspect search index: %w", err)
+	}
+	if triggers == len(searchTriggers) {
+		return true, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(searchIndexSchema); err != nil {
+		return false, fmt.Errorf("failed to create search index: %w", err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM todos_fts;
+		INSERT INTO todos_fts (rowid, title, description, tags)
+		SELECT id, title, description,
+			COALESCE((SELECT group_concat(tag, ' ') FROM todo_tags WHERE todo_id = todos.id), '')
+		FROM todos;
+	`); err != nil {
+		return false, fmt.Errorf("failed to rebuild search index: %w", err)
+	}
+
+	return true, tx.Commit()
+}
+
+// Search returns todos matching a full-text query, most relevant first.
+// See ParseSearchQuery for the query syntax.
+func (s *SQLiteStorage) Search(query string, limit int) ([]SearchResult, error) {
+	q, err := ParseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	if !s.fts {
+		return s.searchLike(q, limit)
+	}
+
+	rows, err := s.conn().Query(`
+		SELECT `+todoColumns+`, f.snip, f.score
+		FROM todos
+		JOIN (
+			SELECT rowid AS fts_id,
+				snippet(todos_fts, -1, ?, ?, '…', 12) AS snip,
+				bm25(todos_fts, 10.0, 2.0, 5.0) AS score
+			FROM todos_fts WHERE todos_fts MATCH ?
+		) f ON f.fts_id = todos.id
+		WHERE todos.deleted_at IS NULL
+		ORDER BY f.score, todos.id DESC
+		LIMIT ?
+	`, HighlightStart, HighlightEnd, q.fts(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search todos: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var r SearchResult
+		todo, err := scanTodo(scanWith(rows, &r.Snippet, &r.Rank))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		r.Todo = *todo
+		results = append(results, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %w", err)
+	}
+
+	return results, nil
+}
+
+// searchCondition matches todos against a search query, using the FTS index
+// when available
+func searchCondition(search string, fts bool) (string, []interface{}, error) {
+	q, err := ParseSearchQuery(search)
+	if err != nil {
+		return "", nil, err
+	}
+	if fts {
+		return "id IN (SELECT rowid FROM todos_fts WHERE todos_fts MATCH ?)", []interface{}{q.fts()}, nil
+	}
+	cond, args := q.like()
+	return cond, args, nil
+}
+
+// searchLike is the fallback for binaries built without FTS5: substring
+// matching with title hits ranked first
+func (s *SQLiteStorage) searchLike(q *SearchQuery, limit int) ([]SearchResult, error) {
+	cond, args := q.like()
+	args = append(args, limit)
+
+	todos, err := s.queryTodos("SELECT "+todoColumns+" FROM todos WHERE deleted_at IS NULL AND "+cond+" ORDER BY updated_at DESC LIMIT ?", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var titleHits, otherHits []SearchResult
+	for _, todo := range todos {
+		r := SearchResult{Todo: todo}
+		if snip, ok := likeSnippet(todo.Title, q.terms); ok {
+			r.Snippet = snip
+			titleHits = append(titleHits, r)
+			continue
+		}
+		r.Snippet, _ = likeSnippet(todo.Description, q.terms)
+		r.Rank = 1
+		otherHits = append(otherHits, r)
+	}
+
+	return append(titleHits, otherHits...), nil
+}
+
+// scanWith appends extra destinations after the todo columns
+func scanWith(row rowScanner, extra ...interface{}) rowScanner {
+	return extraScanner{row: row, extra: extra}
+}
+
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (e extraScanner) Scan(dest ...interface{}) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// SearchQuery is a parsed full-text query
+type SearchQuery struct {
+	groups [][]searchTerm // OR of ANDed terms
+	terms  []searchTerm   // all positive terms, for highlighting
+	not    []searchTerm
+}
+
+type searchTerm struct {
+	text   string
+	phrase bool
+	prefix bool
+}
+
+// ParseSearchQuery parses a search query. Words must all match; "quoted
+// phrases" match exactly; a trailing * matches a prefix (deplo*); OR
+// separates alternatives; NOT or a leading - excludes a term.
+func ParseSearchQuery(query string) (*SearchQuery, error) {
+	q := &SearchQuery{groups: [][]searchTerm{nil}}
+	negate := false
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '-' && !negate:
+			negate = true
+			i++
+			continue
+		}
+
+		var term searchTerm
+		if r == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, Errorf(ErrInvalid, "unterminated phrase at column %d", i+1)
+			}
+			term = searchTerm{text: strings.TrimSpace(string(runes[i+1 : end])), phrase: true}
+			i = end + 1
+			if i < len(runes) && runes[i] == '*' {
+				term.prefix = true
+				i++
+			}
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+
+			switch word {
+			case "OR":
+				if negate || len(q.groups[len(q.groups)-1]) == 0 {
+					return nil, Errorf(ErrInvalid, "OR needs a term on both sides")
+				}
+				q.groups = append(q.groups, nil)
+				continue
+			case "NOT":
+				negate = true
+				continue
+			case "AND":
+				continue
+			}
+
+			term = searchTerm{text: strings.TrimSuffix(word, "*"), prefix: strings.HasSuffix(word, "*")}
+		}
+
+		if strings.Trim(term.text, "*") == "" {
+			negate = false
+			continue
+		}
+
+		if negate {
+			q.not = append(q.not, term)
+			negate = false
+			continue
+		}
+		last := len(q.groups) - 1
+		q.groups[last] = append(q.groups[last], term)
+		q.terms = append(q.terms, term)
+	}
+
+	if len(q.groups[len(q.groups)-1]) == 0 && len(q.groups) > 1 {
+		return nil, Errorf(ErrInvalid, "OR needs a term on both sides")
+	}
+	if len(q.terms) == 0 {
+		return nil, Errorf(ErrInvalid, "search query needs at least one term to match")
+	}
+
+	return q, nil
+}
+
+// fts renders the query in FTS5 syntax, quoting every term so that
+// punctuation in user input can't break the query
+func (q *SearchQuery) fts() string {
+	quote := func(t searchTerm) string {
+		s := `"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`
+		if t.prefix {
+			s += "*"
+		}
+		return s
+	}
+
+	groups := make([]string, len(q.groups))
+	for i, group := range q.groups {
+		parts := make([]string, len(group))
+		for j, t := range group {
+			parts[j] = quote(t)
+		}
+		groups[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+
+	expr := "(" + strings.Join(groups, " OR ") + ")"
+	for _, t := range q.not {
+		expr += " NOT " + quote(t)
+	}
+	return expr
+}
+
+// like renders the query as a LIKE condition over titles, descriptions and tags
+func (q *SearchQuery) like() (string, []interface{}) {
+	var args []interface{}
+	match := func(t searchTerm) string {
+		pattern := "%" + likeEscaper.Replace(t.text) + "%"
+		args = append(args, pattern, pattern, pattern)
+		return `(title LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\' OR id IN (SELECT todo_id FROM todo_tags WHERE tag LIKE ? ESCAPE '\'))`
+	}
+
+	groups := make([]string, len(q.groups))
+	for i, group := range q.groups {
+		parts := make([]string, len(group))
+		for j, t := range group {
+			parts[j] = match(t)
+		}
+		groups[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+
+	cond := "(" + strings.Join(groups, " OR ") + ")"
+	for _, t := range q.not {
+		cond += " AND NOT " + match(t)
+	}
+	return cond, args
+}
+
+// likeSnippetContext is how many bytes of text a snippet keeps on either side
+// of the match, at most
+const likeSnippetContext = 40
+
+// likeSnippet highlights the first term found in text
+func likeSnippet(text string, terms []searchTerm) (string, bool) {
+	for _, t := range terms {
+		idx, end, ok := indexFold(text, t.text)
+		if !ok {
+			continue
+		}
+
+		// Keep whole characters at the edges of the window
+		start := max(0, idx-likeSnippetContext)
+		for start > 0 && !utf8.RuneStart(text[start]) {
+			start++
+		}
+		stop := min(len(text), end+likeSnippetContext)
+		for stop < len(text) && !utf8.RuneStart(text[stop]) {
+			stop--
+		}
+
+		snip := text[start:idx] + HighlightStart + text[idx:end] + HighlightEnd + text[end:stop]
+		if start > 0 {
+			snip = "…" + snip
+		}
+		if stop < len(text) {
+			snip += "…"
+		}
+		return snip, true
+	}
+	return text, false
+}
+
+// indexFold returns the byte offsets in s of the first match of substr under
+// Unicode case folding. The offsets are of s itself, as lowercasing can
+// change the byte length of a character.
+func indexFold(s, substr string) (start, end int, ok bool) {
+	if substr == "" {
+		return 0, 0, false
+	}
+	n := utf8.RuneCountInString(substr)
+	for i := range s {
+		// Case folding maps rune to rune, so a match spans as many runes
+		// as substr
+		j, count := i, 0
+		for j < len(s) && count < n {
+			_, size := utf8.DecodeRuneInString(s[j:])
+			j += size
+			count++
+		}
+		if count < n {
+			break
+		}
+		if strings.EqualFold(s[i:j], substr) {
+			return i, j, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+
+	"todo_cli/internal/model"
+)
+
+func TestSearchLikeFallback(t *testing.T) {
+	s := newTestStorage(t)
+	// Exercise the LIKE search whether or not FTS5 is built in
+	s.fts = false
+
+	todos := map[string]*model.Todo{
+		"50% done":   {},
+		"500 things": {Tags: []string{"#100_percent"}},
+		"a_b":        {},
+		"axb":        {Description: "a%b"},
+		`back\slash`: {Description: "under_score"},
+	}
+	byID := make(map[int64]string)
+	for title, todo := range todos {
+		todo.Title = title
+		mustCreate(t, s, todo)
+		byID[todo.ID] = title
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		// LIKE wildcards in terms match themselves
+		{"50%", []string{"50% done"}},
+		{"%", []string{"50% done", "axb"}},
+		{"a_b", []string{"a_b"}},
+		{"_", []string{"500 things", "a_b", `back\slash`}},
+		{`\`, []string{`back\slash`}},
+		{"0_p", []string{"500 things"}},
+		{"50 -%", []string{"500 things"}},
+	}
+
+	for _, tt := range tests {
+		results, err := s.Search(tt.search, 0)
+		if err != nil {
+			t.Errorf("%q: %v", tt.search, err)
+			continue
+		}
+		var got []string
+		for _, r := range results {
+			got = append(got, byID[r.Todo.ID])
+		}
+		slices.Sort(got)
+		slices.Sort(tt.want)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q:\n got %q\nwant %q", tt.search, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortKey is one key of a composite sort
+type SortKey struct {
+	Field      SortField `json:"field"`
+	Order      SortOrder `json:"order"`
+	NullsFirst bool      `json:"nulls_first,omitempty"` // todos without a due date or priority come first rather than last
+}
+
+// sortColumns are the expressions sort fields order by. Priority 0 means no
+// priority and sorts like a missing due date, as NULL.
+var sortColumns = map[SortField]string{
+	SortByCreated:  "created_at",
+	SortByUpdated:  "updated_at",
+	SortByDueDate:  "due_date",
+	SortByPriority: "NULLIF(priority, 0)",
+	SortByTitle:    "title",
+	SortByDeleted:  "deleted_at",
+}
+
+// sortFields are the fields ParseSort accepts, with their one-letter
+// aliases and the order they sort in without + or -
+var sortFields = []struct {
+	field SortField
+	alias string
+	order SortOrder
+}{
+	{SortByPriority, "p", SortAsc}, // 1 (highest) first
+	{SortByDueDate, "d", SortAsc},
+	{SortByCreated, "c", SortDesc},
+	{SortByUpdated, "u", SortDesc},
+	{SortByTitle, "t", SortAsc},
+}
+
+// ParseSort parses a comma-separated list of sort keys such as
+// "due,priority,-created". A key sorts ascending with a + prefix,
+// descending with -, and otherwise in its default order, or in order if
+// that is set. Todos without a due date or priority sort last in either
+// direction unless the key ends in :nulls-first.
+func ParseSort(s string, order SortOrder) ([]SortKey, error) {
+	var keys []SortKey
+	seen := make(map[SortField]bool)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		name, nulls, _ := strings.Cut(part, ":")
+
+		key := SortKey{Order: order}
+		switch {
+		case strings.HasPrefix(name, "-"):
+			key.Order = SortDesc
+			name = name[1:]
+		case strings.HasPrefix(name, "+"):
+			key.Order = SortAsc
+			name = name[1:]
+		}
+
+		for _, f := range sortFields {
+			if name == string(f.field) || name == f.alias {
+				key.Field = f.field
+				if key.Order == "" {
+					key.Order = f.order
+				}
+			}
+		}
+		if key.Field == "" {
+			return nil, Errorf(ErrInvalid, "unknown sort key %q (use priority, due, created, updated or title)", name)
+		}
+		if seen[key.Field] {
+			return nil, Errorf(ErrInvalid, "sort key %s given twice", key.Field)
+		}
+		seen[key.Field] = true
+
+		switch nulls {
+		case "", "nulls-last":
+		case "nulls-first":
+			key.NullsFirst = true
+		default:
+			return nil, Errorf(ErrInvalid, "unknown sort option %q (use nulls-first or nulls-last)", nulls)
+		}
+
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// String renders the key as ParseSort reads it
+func (k SortKey) String() string {
+	s := "+" + string(k.Field)
+	if k.Order == SortDesc {
+		s = "-" + string(k.Field)
+	}
+	if k.NullsFirst {
+		s += ":nulls-first"
+	}
+	return s
+}
+
+// sortKeys returns the sort of a filter, falling back to its single
+// SortBy field and to the newest todos first
+func sortKeys(filter Filter) []SortKey {
+	if len(filter.Sort) > 0 {
+		return filter.Sort
+	}
+	key := SortKey{Field: filter.SortBy, Order: SortDesc}
+	if key.Field == "" {
+		key.Field = SortByCreated
+	}
+	if filter.SortOrder == SortAsc {
+		key.Order = SortAsc
+	}
+	return []SortKey{key}
+}
+
+// afterCondition matches the todos that come after the todo with the given
+// ID in the order of filter, for keyset pagination. The todo's own sort
+// values are looked up by subquery, so it must still exist.
+func afterCondition(filter Filter, id int64) (string, []interface{}) {
+	var terms []string
+	var args []interface{}
+
+	// Sort keys compare in order: equal on every key so far, then after on
+	// this one
+	var equal []string
+	var equalArgs []interface{}
+	for _, key := range sortKeys(filter) {
+		column, ok := sortColumns[key.Field]
+		if !ok {
+			column = sortColumns[SortByCreated]
+		}
+		value := "(SELECT " + column + " FROM todos k WHERE k.id = ?)"
+
+		op := "<"
+		if key.Order == SortAsc {
+			op = ">"
+		}
+		after := fmt.Sprintf("(%s %s %s OR (%s IS NULL AND %s IS NOT NULL))", column, op, value, column, value)
+		if key.NullsFirst {
+			after = fmt.Sprintf("(%s %s %s OR (%s IS NULL AND %s IS NOT NULL))", column, op, value, value, column)
+		}
+
+		terms = append(terms, strings.Join(slices.Concat(equal, []string{after}), " AND "))
+		args = append(args, equalArgs...)
+		args = append(args, id, id)
+
+		equal = append(equal, fmt.Sprintf("%s IS %s", column, value))
+		equalArgs = append(equalArgs, id)
+	}
+
+	// Then the ID tiebreak of orderBy
+	op := ">"
+	if tiebreak(filter) == SortDesc {
+		op = "<"
+	}
+	terms = append(terms, strings.Join(append(equal, "id "+op+" ?"), " AND "))
+	args = append(args, equalArgs...)
+	args = append(args, id)
+
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// orderBy renders the ORDER BY clause of a filter. Ties are broken by ID so
+// the order is stable.
+func orderBy(filter Filter) string {
+	var terms []string
+	for _, key := range sortKeys(filter) {
+		column, ok := sortColumns[key.Field]
+		if !ok {
+			column = sortColumns[SortByCreated]
+		}
+		order := "DESC"
+		if key.Order == SortAsc {
+			order = "ASC"
+		}
+		nulls := "NULLS LAST"
+		if key.NullsFirst {
+			nulls = "NULLS FIRST"
+		}
+		terms = append(terms, fmt.Sprintf("%s %s %s", column, order, nulls))
+	}
+
+	terms = append(terms, "id "+strings.ToUpper(string(tiebreak(filter))))
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// tiebreak orders todos that sort the same by ID, in the direction of the
+// created key if there is one, as IDs follow creation
+func tiebreak(filter Filter) SortOrder {
+	for _, key := range sortKeys(filter) {
+		if key.Field == SortByCreated {
+			return key.Order
+		}
+	}
+	return SortAsc
+}
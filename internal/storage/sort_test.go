@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// newSortFixture creates todos with missing and repeated values in every
+// sort key, so pages split runs of equal keys and of NULLs
+func newSortFixture(t *testing.T) *SQLiteStorage {
+	t.Helper()
+	s := newTestStorage(t)
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := range 23 {
+		todo := &model.Todo{
+			Title:    []string{"alpha", "beta", "gamma"}[i%3],
+			Priority: []int{0, 1, 2, 0, 3, 1, 0}[i%7],
+		}
+		if i%4 != 0 {
+			due := base.AddDate(0, 0, i%5)
+			todo.DueDate = &due
+		}
+		mustCreate(t, s, todo)
+
+		// Several todos share each creation and update time
+		created := base.Add(-time.Duration(i/3) * time.Hour)
+		updated := base.Add(time.Duration(i%4) * time.Minute)
+		if _, err := s.db.Exec("UPDATE todos SET created_at = ?, updated_at = ? WHERE id = ?", created, updated, todo.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func ids(todos []model.Todo) []int64 {
+	ids := make([]int64, len(todos))
+	for i, todo := range todos {
+		ids[i] = todo.ID
+	}
+	return ids
+}
+
+func TestPagination(t *testing.T) {
+	s := newSortFixture(t)
+
+	filters := map[string]Filter{
+		"default":   {},
+		"legacy":    {SortBy: SortByDueDate, SortOrder: SortAsc},
+		"legacy-pr": {SortBy: SortByPriority},
+	}
+	for _, spec := range []string{
+		"created",
+		"+created",
+		"due",
+		"-due",
+		"due:nulls-first",
+		"-due:nulls-first",
+		"priority,title",
+		"-priority:nulls-first,-title",
+		"due,priority,-created",
+		"title,-updated",
+		"-updated,due:nulls-first,-priority",
+		"title,+created",
+	} {
+		sort, err := ParseSort(spec, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		filters[spec] = Filter{Sort: sort}
+	}
+
+	for name, filter := range filters {
+		t.Run(name, func(t *testing.T) {
+			all, err := s.List(filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := ids(all)
+			if len(want) != 23 {
+				t.Fatalf("listed %d todos, want 23", len(want))
+			}
+
+			for _, size := range []int{1, 3, 7, 23, 50} {
+				// Keyset: each page starts after the last todo of the previous one
+				var keyset []int64
+				page := filter
+				page.Limit = size
+				for range len(want) + 1 {
+					todos, err := s.List(page)
+					if err != nil {
+						t.Fatal(err)
+					}
+					keyset = append(keyset, ids(todos)...)
+					if len(todos) < size {
+						break
+					}
+					page.AfterID = todos[len(todos)-1].ID
+				}
+				if !slices.Equal(keyset, want) {
+					t.Errorf("keyset pages of %d:\n got %v\nwant %v", size, keyset, want)
+				}
+
+				// Offset
+				var offset []int64
+				page = filter
+				page.Limit = size
+				for page.Offset = 0; page.Offset < len(want)+size; page.Offset += size {
+					todos, err := s.List(page)
+					if err != nil {
+						t.Fatal(err)
+					}
+					offset = append(offset, ids(todos)...)
+				}
+				if !slices.Equal(offset, want) {
+					t.Errorf("offset pages of %d:\n got %v\nwant %v", size, offset, want)
+				}
+			}
+
+			// Stream yields the same order
+			var streamed []int64
+			for todo, err := range s.Stream(filter) {
+				if err != nil {
+					t.Fatal(err)
+				}
+				streamed = append(streamed, todo.ID)
+			}
+			if !slices.Equal(streamed, want) {
+				t.Errorf("stream:\n got %v\nwant %v", streamed, want)
+			}
+		})
+	}
+}
+
+func TestOrderByNulls(t *testing.T) {
+	s := newSortFixture(t)
+
+	for _, tt := range []struct {
+		spec      string
+		nullsLast bool
+	}{
+		{"due", true},
+		{"-due", true},
+		{"due:nulls-first", false},
+		{"-due:nulls-first", false},
+	} {
+		sort, err := ParseSort(tt.spec, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		todos, err := s.List(Filter{Sort: sort})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 6 of the 23 todos have no due date, all at one end
+		undated := slices.IndexFunc(todos, func(todo model.Todo) bool { return todo.DueDate == nil })
+		if !tt.nullsLast {
+			undated = 0
+		} else if undated != len(todos)-6 {
+			t.Errorf("%s: the first todo without a due date is at %d, want %d", tt.spec, undated, len(todos)-6)
+		}
+		for _, todo := range todos[undated : undated+6] {
+			if todo.DueDate != nil {
+				t.Errorf("%s: #%d with a due date among those without", tt.spec, todo.ID)
+			}
+		}
+
+		// Within the dated todos, the direction holds
+		for i := 1; i < len(todos); i++ {
+			a, b := todos[i-1].DueDate, todos[i].DueDate
+			if a == nil || b == nil {
+				continue
+			}
+			if sort[0].Order == SortAsc && a.After(*b) || sort[0].Order == SortDesc && a.Before(*b) {
+				t.Errorf("%s: %s before %s", tt.spec, a, b)
+			}
+		}
+	}
+}
+
+func TestAfterDeletedCursor(t *testing.T) {
+	s := newSortFixture(t)
+	todos, err := s.List(Filter{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A cursor that no longer exists matches nothing rather than restarting
+	if _, err := s.db.Exec("DELETE FROM todos WHERE id = ?", todos[4].ID); err != nil {
+		t.Fatal(err)
+	}
+	next, err := s.List(Filter{Limit: 5, AfterID: todos[4].ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next) != 0 {
+		t.Errorf("got %v after a deleted cursor, want nothing", ids(next))
+	}
+}
@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"iter"
 	"os"
 	"path/filepath"
 	"strings"
@@ -15,28 +17,29 @@ import (
 	"todo_cli/internal/model"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS todos (
-    id INTEGER PRIMARY KEY AUTOINCREMENT,
-    title TEXT NOT NULL,
-    description TEXT DEFAULT '',
-    tags TEXT DEFAULT '[]',
-    due_date DATETIME,
-    created_at DATETIME NOT NULL,
-    updated_at DATETIME NOT NULL,
-    completed_at DATETIME,
-    completed INTEGER DEFAULT 0,
-    priority INTEGER DEFAULT 0
-);
-
-CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos(completed);
-CREATE INDEX IF NOT EXISTS idx_todos_due_date ON todos(due_date);
-CREATE INDEX IF NOT EXISTS idx_todos_priority ON todos(priority);
-`
+// todoColumns lists the columns read by scanTodo, in scan order
+const todoColumns = `id, title, description,
+	(SELECT json_group_array(tag) FROM (SELECT tag FROM todo_tags WHERE todo_id = todos.id ORDER BY position)),
+	due_date, created_at, updated_at, completed_at, completed, priority, recurrence, parent_id,
+	(SELECT COUNT(*) FROM todos c WHERE c.parent_id = todos.id AND c.deleted_at IS NULL AND c.archived_at IS NULL),
+	(SELECT COUNT(*) FROM todos c WHERE c.parent_id = todos.id AND c.deleted_at IS NULL AND c.archived_at IS NULL AND c.completed = 1),
+	project_id, COALESCE((SELECT name FROM projects p WHERE p.id = todos.project_id), ''),
+	(SELECT COUNT(*) FROM todo_dependencies d JOIN todos b ON b.id = d.blocked_by_id
+		WHERE d.todo_id = todos.id AND b.completed = 0 AND b.deleted_at IS NULL),
+	deleted_at, archived_at, version, due_has_time`
+
+// openBlockersCondition matches todos waiting on an open blocker
+const openBlockersCondition = `EXISTS (SELECT 1 FROM todo_dependencies d JOIN todos b ON b.id = d.blocked_by_id
+	WHERE d.todo_id = todos.id AND b.completed = 0 AND b.deleted_at IS NULL)`
 
 // SQLiteStorage implements Storage using SQLite
 type SQLiteStorage struct {
-	db *sql.DB
+	db        *sql.DB
+	tx        *sql.Tx         // set on the storage passed to a Batch callback
+	ctx       context.Context // set by WithContext, nil for none
+	fts       bool            // full-text search index available
+	undoGroup int64           // operation opened by Batch, 0 if none
+	undoLabel string          // name of that operation
 }
 
 // NewSQLiteStorage creates a new SQLite storage instance
@@ -46,28 +49,32 @@ func NewSQLiteStorage() (*SQLiteStorage, error) {
 		return nil, fmt.Errorf("failed to get database path: %w", err)
 	}
 
-	// Ensure directory exists
-	dir := filepath.Dir(dbPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create data directory: %w", err)
-	}
+	return NewSQLiteStorageWithPath(dbPath)
+}
 
-	db, err := sql.Open("sqlite3", dbPath+"?_foreign_keys=on")
+// NewSQLiteStorageWithPath creates a new SQLite storage at a specific path,
+// applying any pending schema migrations
+func NewSQLiteStorageWithPath(dbPath string) (*SQLiteStorage, error) {
+	db, err := openDB(dbPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	// Initialize schema
-	if _, err := db.Exec(schema); err != nil {
+	if err := migrate(db); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	return &SQLiteStorage{db: db}, nil
+	fts, err := initSearchIndex(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStorage{db: db, fts: fts}, nil
 }
 
-// NewSQLiteStorageWithPath creates a new SQLite storage at a specific path
-func NewSQLiteStorageWithPath(dbPath string) (*SQLiteStorage, error) {
+func openDB(dbPath string) (*sql.DB, error) {
 	// Ensure directory exists
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -79,13 +86,12 @@ func NewSQLiteStorageWithPath(dbPath string) (*SQLiteStorage, error) {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Initialize schema
-	if _, err := db.Exec(schema); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
-	}
+	return db, nil
+}
 
-	return &SQLiteStorage{db: db}, nil
+// DefaultDBPath returns the location of the database under the XDG data directory
+func DefaultDBPath() (string, error) {
+	return getDBPath()
 }
 
 func getDBPath() (string, error) {
@@ -98,17 +104,25 @@ func (s *SQLiteStorage) Create(todo *model.Todo) error {
 	todo.CreatedAt = now
 	todo.UpdatedAt = now
 
-	tagsJSON, err := json.Marshal(todo.Tags)
+	if todo.ParentID != nil {
+		if err := s.checkLive(*todo.ParentID, "parent"); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.begin()
 	if err != nil {
-		return fmt.Errorf("failed to marshal tags: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	result, err := s.db.Exec(`
-		INSERT INTO todos (title, description, tags, due_date, created_at, updated_at, completed_at, completed, priority)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, todo.Title, todo.Description, string(tagsJSON), nullableTime(todo.DueDate),
+	result, err := tx.Exec(`
+		INSERT INTO todos (title, description, due_date, due_has_time, created_at, updated_at, completed_at, completed, priority, recurrence, parent_id, project_id, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, todo.Title, todo.Description, nullableTime(todo.DueDate), boolToInt(todo.DueHasTime && todo.DueDate != nil),
 		todo.CreatedAt, todo.UpdatedAt, nullableTime(todo.CompletedAt),
-		boolToInt(todo.Completed), todo.Priority)
+		boolToInt(todo.Completed), todo.Priority, todo.Recurrence, nullableInt(todo.ParentID),
+		nullableInt(todo.ProjectID), nullableTime(todo.ArchivedAt))
 
 	if err != nil {
 		return fmt.Errorf("failed to insert todo: %w", err)
@@ -119,20 +133,47 @@ func (s *SQLiteStorage) Create(todo *model.Todo) error {
 		return fmt.Errorf("failed to get last insert ID: %w", err)
 	}
 
+	if err := setTags(tx, id, todo.Tags); err != nil {
+		return err
+	}
+
+	created, err := loadTodo(tx, id)
+	if err != nil {
+		return err
+	}
+	if err := recordChanges(tx, EventCreate, id, nil, created); err != nil {
+		return err
+	}
+
+	j, err := s.beginJournal(tx, fmt.Sprintf("add #%d %q", id, todo.Title))
+	if err != nil {
+		return err
+	}
+	j.created(id)
+	if err := j.finish(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit todo: %w", err)
+	}
+
 	todo.ID = id
+	todo.Version = created.Version
 	return nil
 }
 
 // GetByID retrieves a todo by its ID
 func (s *SQLiteStorage) GetByID(id int64) (*model.Todo, error) {
-	row := s.db.QueryRow(`
-		SELECT id, title, description, tags, due_date, created_at, updated_at, completed_at, completed, priority
-		FROM todos WHERE id = ?
-	`, id)
+	return loadTodo(s.conn(), id)
+}
+
+func loadTodo(q dbtx, id int64) (*model.Todo, error) {
+	row := q.QueryRow("SELECT "+todoColumns+" FROM todos WHERE id = ?", id)
 
 	todo, err := scanTodo(row)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("todo with ID %d not found", id)
+		return nil, Errorf(ErrNotFound, "todo with ID %d not found", id)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get todo: %w", err)
@@ -143,134 +184,268 @@ func (s *SQLiteStorage) GetByID(id int64) (*model.Todo, error) {
 
 // List retrieves todos matching the given filter
 func (s *SQLiteStorage) List(filter Filter) ([]model.Todo, error) {
-	query := "SELECT id, title, description, tags, due_date, created_at, updated_at, completed_at, completed, priority FROM todos WHERE 1=1"
+	query, args, err := s.listQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.queryTodos(query, args...)
+}
+
+// Stream yields the todos matching filter one at a time instead of loading
+// them all into memory. The query stays open until the loop ends, so make no
+// changes through a Batch storage while iterating it.
+func (s *SQLiteStorage) Stream(filter Filter) iter.Seq2[*model.Todo, error] {
+	return func(yield func(*model.Todo, error) bool) {
+		query, args, err := s.listQuery(filter)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		rows, err := s.conn().Query(query, args...)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to query todos: %w", err))
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			todo, err := scanTodo(rows)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to scan todo: %w", err))
+				return
+			}
+			if !yield(todo, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, fmt.Errorf("error iterating todos: %w", err))
+		}
+	}
+}
+
+// listQuery builds the SELECT statement of List and Stream
+func (s *SQLiteStorage) listQuery(filter Filter) (string, []interface{}, error) {
+	query := "SELECT " + todoColumns + " FROM todos WHERE deleted_at IS NULL"
+	if filter.Trashed {
+		query = "SELECT " + todoColumns + " FROM todos WHERE deleted_at IS NOT NULL"
+	}
 	args := []interface{}{}
 
+	if !filter.IncludeArchived && !filter.Trashed {
+		query += " AND archived_at IS NULL"
+	}
+
 	// Completed filter
 	if filter.Completed != nil {
 		query += " AND completed = ?"
 		args = append(args, boolToInt(*filter.Completed))
 	}
 
-	// Tags filter
-	if len(filter.Tags) > 0 {
-		for _, tag := range filter.Tags {
-			query += " AND tags LIKE ?"
-			args = append(args, "%"+tag+"%")
+	// Parent filter
+	if filter.ParentID != nil {
+		query += " AND parent_id = ?"
+		args = append(args, *filter.ParentID)
+	}
+
+	// Project filter
+	if filter.Project != "" {
+		query += " AND project_id = (SELECT id FROM projects WHERE name = ?)"
+		args = append(args, filter.Project)
+	}
+
+	// Dependency filter
+	if filter.Blocked != nil {
+		if *filter.Blocked {
+			query += " AND " + openBlockersCondition
+		} else {
+			query += " AND NOT " + openBlockersCondition
 		}
 	}
 
+	// Priority filter. Todos without a priority are outside any range.
+	if filter.PriorityMin > 0 {
+		query += " AND priority >= ?"
+		args = append(args, filter.PriorityMin)
+	}
+	if filter.PriorityMax > 0 {
+		query += " AND priority BETWEEN 1 AND ?"
+		args = append(args, filter.PriorityMax)
+	}
+
+	// Tags filters (exact matches)
+	if len(filter.Tags) > 0 {
+		query += " AND id IN (SELECT todo_id FROM todo_tags WHERE tag IN (" + placeholders(len(filter.Tags)) +
+			") GROUP BY todo_id HAVING COUNT(DISTINCT tag) = ?)"
+		args = append(args, stringArgs(filter.Tags)...)
+		args = append(args, len(uniqueStrings(filter.Tags)))
+	}
+	if len(filter.TagsAny) > 0 {
+		query += " AND id IN (SELECT todo_id FROM todo_tags WHERE tag IN (" + placeholders(len(filter.TagsAny)) + "))"
+		args = append(args, stringArgs(filter.TagsAny)...)
+	}
+	if len(filter.TagsNone) > 0 {
+		query += " AND id NOT IN (SELECT todo_id FROM todo_tags WHERE tag IN (" + placeholders(len(filter.TagsNone)) + "))"
+		args = append(args, stringArgs(filter.TagsNone)...)
+	}
+
 	// Search filter
 	if filter.Search != "" {
-		query += " AND (title LIKE ? OR description LIKE ?)"
-		searchPattern := "%" + filter.Search + "%"
-		args = append(args, searchPattern, searchPattern)
+		cond, condArgs, err := searchCondition(filter.Search, s.fts)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " AND " + cond
+		args = append(args, condArgs...)
 	}
 
-	// Due date filter
+	// Query language filter
+	if filter.Query != "" {
+		cond, condArgs, err := s.compileQuery(filter.Query)
+		if err != nil {
+			return "", nil, err
+		}
+		query += " AND " + cond
+		args = append(args, condArgs...)
+	}
+
+	// Due date filter. Days start at midnight in time.Local and are
+	// compared in UTC, like the stored due dates.
 	if filter.DueDate != nil {
-		now := time.Now()
+		now := time.Now().In(time.Local)
 		switch filter.DueDate.Type {
 		case DueToday:
-			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 			endOfDay := startOfDay.AddDate(0, 0, 1)
 			query += " AND due_date >= ? AND due_date < ?"
-			args = append(args, startOfDay, endOfDay)
+			args = append(args, startOfDay.UTC(), endOfDay.UTC())
 		case DueTomorrow:
-			startOfTomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+			startOfTomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
 			endOfTomorrow := startOfTomorrow.AddDate(0, 0, 1)
 			query += " AND due_date >= ? AND due_date < ?"
-			args = append(args, startOfTomorrow, endOfTomorrow)
+			args = append(args, startOfTomorrow.UTC(), endOfTomorrow.UTC())
 		case DueNextWeek:
-			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 			endOfWeek := startOfDay.AddDate(0, 0, 7)
 			query += " AND due_date >= ? AND due_date < ?"
-			args = append(args, startOfDay, endOfWeek)
+			args = append(args, startOfDay.UTC(), endOfWeek.UTC())
 		case DueOverdue:
-			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+			startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
 			query += " AND due_date < ? AND completed = 0"
-			args = append(args, startOfDay)
+			args = append(args, startOfDay.UTC())
 		case DueSpecific:
 			if filter.DueDate.SpecificDate != nil {
+				// The calendar date as written, even if it was saved in a
+				// view from another zone
 				d := *filter.DueDate.SpecificDate
-				startOfDay := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, d.Location())
+				startOfDay := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.Local)
 				endOfDay := startOfDay.AddDate(0, 0, 1)
 				query += " AND due_date >= ? AND due_date < ?"
-				args = append(args, startOfDay, endOfDay)
+				args = append(args, startOfDay.UTC(), endOfDay.UTC())
 			}
 		}
 	}
 
-	// Sorting
-	sortField := "created_at"
-	switch filter.SortBy {
-	case SortByCreated:
-		sortField = "created_at"
-	case SortByUpdated:
-		sortField = "updated_at"
-	case SortByDueDate:
-		sortField = "due_date"
-	case SortByPriority:
-		sortField = "priority"
-	case SortByTitle:
-		sortField = "title"
+	if filter.NoDue {
+		query += " AND due_date IS NULL"
 	}
 
-	sortOrder := "DESC"
-	if filter.SortOrder == SortAsc {
-		sortOrder = "ASC"
+	// Timestamp ranges
+	ranges := []struct {
+		column string
+		bounds *TimeRange
+	}{
+		{"due_date", filter.DueRange},
+		{"created_at", filter.CreatedRange},
+		{"updated_at", filter.UpdatedRange},
+		{"completed_at", filter.CompletedRange},
 	}
-
-	// Special handling for priority sort (0 should go last)
-	if filter.SortBy == SortByPriority {
-		if sortOrder == "ASC" {
-			query += fmt.Sprintf(" ORDER BY CASE WHEN priority = 0 THEN 999 ELSE priority END %s", sortOrder)
-		} else {
-			query += fmt.Sprintf(" ORDER BY CASE WHEN priority = 0 THEN -1 ELSE priority END %s", sortOrder)
+	for _, r := range ranges {
+		if r.bounds == nil {
+			continue
+		}
+		cond, condArgs, err := rangeCondition(r.column, r.bounds)
+		if err != nil {
+			return "", nil, err
+		}
+		if cond != "" {
+			query += " AND " + cond
+			args = append(args, condArgs...)
 		}
-	} else {
-		query += fmt.Sprintf(" ORDER BY %s %s", sortField, sortOrder)
 	}
 
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query todos: %w", err)
+	// Keyset pagination
+	if filter.AfterID != 0 {
+		cond, condArgs := afterCondition(filter, filter.AfterID)
+		query += " AND " + cond
+		args = append(args, condArgs...)
 	}
-	defer rows.Close()
 
-	var todos []model.Todo
-	for rows.Next() {
-		todo, err := scanTodoRows(rows)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan todo: %w", err)
-		}
-		todos = append(todos, *todo)
-	}
+	query += orderBy(filter)
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating todos: %w", err)
+	// Offset pagination; SQLite needs a LIMIT for an OFFSET, -1 for none
+	if filter.Limit > 0 || filter.Offset > 0 {
+		limit := filter.Limit
+		if limit <= 0 {
+			limit = -1
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, filter.Offset)
 	}
 
-	return todos, nil
+	return query, args, nil
 }
 
 // Update updates an existing todo
 func (s *SQLiteStorage) Update(todo *model.Todo) error {
 	todo.UpdatedAt = time.Now().UTC()
 
-	tagsJSON, err := json.Marshal(todo.Tags)
+	// Reopening a todo brings it back out of the archive
+	if !todo.Completed {
+		todo.ArchivedAt = nil
+	}
+
+	if todo.ParentID != nil {
+		if err := s.checkParent(todo.ID, *todo.ParentID); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.begin()
 	if err != nil {
-		return fmt.Errorf("failed to marshal tags: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	result, err := s.db.Exec(`
+	before, err := loadTodo(tx, todo.ID)
+	if err != nil {
+		return err
+	}
+	if before.IsDeleted() {
+		return Errorf(ErrConflict, "todo #%d is in the trash (restore it first)", todo.ID)
+	}
+	if before.Version != todo.Version {
+		return &ConflictError{Current: before}
+	}
+
+	j, err := s.beginJournal(tx, fmt.Sprintf("edit #%d", todo.ID))
+	if err != nil {
+		return err
+	}
+	if err := j.capture(tx, todo.ID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
 		UPDATE todos SET
-			title = ?, description = ?, tags = ?, due_date = ?,
-			updated_at = ?, completed_at = ?, completed = ?, priority = ?
-		WHERE id = ?
-	`, todo.Title, todo.Description, string(tagsJSON), nullableTime(todo.DueDate),
+			title = ?, description = ?, due_date = ?, due_has_time = ?,
+			updated_at = ?, completed_at = ?, completed = ?, priority = ?, recurrence = ?,
+			parent_id = ?, project_id = ?, archived_at = ?, version = version + 1
+		WHERE id = ? AND version = ?
+	`, todo.Title, todo.Description, nullableTime(todo.DueDate), boolToInt(todo.DueHasTime && todo.DueDate != nil),
 		todo.UpdatedAt, nullableTime(todo.CompletedAt), boolToInt(todo.Completed),
-		todo.Priority, todo.ID)
+		todo.Priority, todo.Recurrence, nullableInt(todo.ParentID), nullableInt(todo.ProjectID),
+		nullableTime(todo.ArchivedAt), todo.ID, todo.Version)
 
 	if err != nil {
 		return fmt.Errorf("failed to update todo: %w", err)
@@ -282,59 +457,217 @@ func (s *SQLiteStorage) Update(todo *model.Todo) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("todo with ID %d not found", todo.ID)
+		return &ConflictError{Current: before}
+	}
+
+	if err := setTags(tx, todo.ID, todo.Tags); err != nil {
+		return err
+	}
+
+	after, err := loadTodo(tx, todo.ID)
+	if err != nil {
+		return err
+	}
+	if err := recordChanges(tx, EventUpdate, todo.ID, before, after); err != nil {
+		return err
+	}
+	if err := j.finish(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit todo: %w", err)
+	}
+
+	todo.Version = after.Version
+	return nil
+}
+
+// setTags replaces the tags of a todo, keeping their order
+func setTags(tx dbtx, todoID int64, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM todo_tags WHERE todo_id = ?", todoID); err != nil {
+		return fmt.Errorf("failed to clear tags: %w", err)
+	}
+
+	for i, tag := range tags {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO todo_tags (todo_id, tag, position) VALUES (?, ?, ?)",
+			todoID, tag, i,
+		); err != nil {
+			return fmt.Errorf("failed to save tag %s: %w", tag, err)
+		}
 	}
 
 	return nil
 }
 
-// Delete removes a todo by ID
+// checkParent verifies that parentID exists and is not id itself or one of its descendants
+func (s *SQLiteStorage) checkParent(id, parentID int64) error {
+	if err := s.checkLive(parentID, "parent"); err != nil {
+		return err
+	}
+
+	var cycle bool
+	err := s.conn().QueryRow(`
+		WITH RECURSIVE ancestors(id) AS (
+			SELECT ?
+			UNION
+			SELECT t.parent_id FROM todos t JOIN ancestors a ON t.id = a.id WHERE t.parent_id IS NOT NULL
+		)
+		SELECT EXISTS(SELECT 1 FROM ancestors WHERE id = ?)
+	`, parentID, id).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("failed to check parent: %w", err)
+	}
+	if cycle {
+		return Errorf(ErrInvalid, "todo #%d cannot be a subtask of #%d: it would become its own ancestor", id, parentID)
+	}
+
+	return nil
+}
+
+// checkLive verifies that a referenced todo exists and is not in the trash
+func (s *SQLiteStorage) checkLive(id int64, role string) error {
+	todo, err := s.GetByID(id)
+	if err != nil {
+		return Errorf(ErrInvalid, "invalid %s: %w", role, err)
+	}
+	if todo.IsDeleted() {
+		return Errorf(ErrInvalid, "invalid %s: todo #%d is in the trash", role, id)
+	}
+	return nil
+}
+
+// Delete moves a todo to the trash, promoting its subtasks to its parent
 func (s *SQLiteStorage) Delete(id int64) error {
-	result, err := s.db.Exec("DELETE FROM todos WHERE id = ?", id)
+	return s.DeleteWithPolicy(id, OrphanPromote)
+}
+
+// DeleteWithPolicy moves a todo to the trash, handling its subtasks
+// according to policy. Trashed todos can be restored until they are purged.
+func (s *SQLiteStorage) DeleteWithPolicy(id int64, policy OrphanPolicy) error {
+	todo, err := s.GetByID(id)
 	if err != nil {
-		return fmt.Errorf("failed to delete todo: %w", err)
+		return err
+	}
+	if todo.IsDeleted() {
+		return Errorf(ErrConflict, "todo #%d is already in the trash", id)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	tx, err := s.begin()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("todo with ID %d not found", id)
+	j, err := s.beginJournal(tx, fmt.Sprintf("delete #%d %q", id, todo.Title))
+	if err != nil {
+		return err
+	}
+	if err := j.capture(tx, id); err != nil {
+		return err
+	}
+
+	// Subtasks trashed with their parent share its deleted_at, so that
+	// restoring the parent brings them back too
+	now := time.Now().UTC()
+	trashed := []model.Todo{*todo}
+
+	switch policy {
+	case OrphanRefuse:
+		if todo.HasSubtasks() {
+			return Errorf(ErrConflict, "todo #%d has %d subtask(s)", id, todo.SubtaskTotal)
+		}
+	case OrphanPromote:
+		children, err := selectTodos(tx, "SELECT "+todoColumns+" FROM todos WHERE parent_id = ? AND deleted_at IS NULL", id)
+		if err != nil {
+			return fmt.Errorf("failed to load subtasks: %w", err)
+		}
+		for _, child := range children {
+			if err := j.capture(tx, child.ID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(
+			"UPDATE todos SET parent_id = ?, version = version + 1 WHERE parent_id = ? AND deleted_at IS NULL",
+			nullableInt(todo.ParentID), id,
+		); err != nil {
+			return fmt.Errorf("failed to promote subtasks: %w", err)
+		}
+		for i := range children {
+			promoted := children[i]
+			promoted.ParentID = todo.ParentID
+			if err := recordChanges(tx, EventUpdate, promoted.ID, &children[i], &promoted); err != nil {
+				return err
+			}
+		}
+	case OrphanCascade:
+		descendants, err := selectTodos(tx, `
+			WITH RECURSIVE descendants(id) AS (
+				SELECT id FROM todos WHERE parent_id = ? AND deleted_at IS NULL
+				UNION
+				SELECT t.id FROM todos t JOIN descendants d ON t.parent_id = d.id WHERE t.deleted_at IS NULL
+			)
+			SELECT `+todoColumns+` FROM todos WHERE id IN descendants
+		`, id)
+		if err != nil {
+			return fmt.Errorf("failed to load subtasks: %w", err)
+		}
+		trashed = append(trashed, descendants...)
+	default:
+		return Errorf(ErrInvalid, "unknown orphan policy: %s", policy)
+	}
+
+	for i := range trashed {
+		t := &trashed[i]
+		if err := j.capture(tx, t.ID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE todos SET deleted_at = ?, version = version + 1 WHERE id = ?", now, t.ID); err != nil {
+			return fmt.Errorf("failed to delete todo: %w", err)
+		}
+		after := *t
+		after.DeletedAt = &now
+		if err := recordChanges(tx, EventTrash, t.ID, t, &after); err != nil {
+			return err
+		}
+	}
+
+	if err := j.finish(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", err)
 	}
 
 	return nil
 }
 
-// GetAllTags returns all unique tags from all todos
-func (s *SQLiteStorage) GetAllTags() ([]string, error) {
-	rows, err := s.db.Query("SELECT tags FROM todos")
+// GetAllTags returns every tag in use with the number of todos carrying it,
+// most used first
+func (s *SQLiteStorage) GetAllTags() ([]TagCount, error) {
+	rows, err := s.conn().Query(`
+		SELECT tag, COUNT(*) FROM todo_tags
+		WHERE todo_id IN (SELECT id FROM todos WHERE deleted_at IS NULL)
+		GROUP BY tag ORDER BY COUNT(*) DESC, tag
+	`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query tags: %w", err)
 	}
 	defer rows.Close()
 
-	tagSet := make(map[string]struct{})
+	var tags []TagCount
 	for rows.Next() {
-		var tagsJSON string
-		if err := rows.Scan(&tagsJSON); err != nil {
+		var tc TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
 			return nil, fmt.Errorf("failed to scan tags: %w", err)
 		}
-
-		var tags []string
-		if err := json.Unmarshal([]byte(tagsJSON), &tags); err != nil {
-			continue // Skip invalid JSON
-		}
-
-		for _, tag := range tags {
-			tagSet[tag] = struct{}{}
-		}
+		tags = append(tags, tc)
 	}
 
-	tags := make([]string, 0, len(tagSet))
-	for tag := range tagSet {
-		tags = append(tags, tag)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
 	}
 
 	return tags, nil
@@ -347,16 +680,24 @@ func (s *SQLiteStorage) Close() error {
 
 // Helper functions
 
-func scanTodo(row *sql.Row) (*model.Todo, error) {
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTodo(row rowScanner) (*model.Todo, error) {
 	var todo model.Todo
 	var tagsJSON string
-	var dueDate, completedAt sql.NullTime
+	var dueDate, completedAt, deletedAt, archivedAt sql.NullTime
 	var completed int
+	var parentID, projectID sql.NullInt64
 
 	err := row.Scan(
 		&todo.ID, &todo.Title, &todo.Description, &tagsJSON,
 		&dueDate, &todo.CreatedAt, &todo.UpdatedAt, &completedAt,
-		&completed, &todo.Priority,
+		&completed, &todo.Priority, &todo.Recurrence, &parentID,
+		&todo.SubtaskTotal, &todo.SubtaskDone, &projectID, &todo.Project,
+		&todo.OpenBlockers, &deletedAt, &archivedAt, &todo.Version, &todo.DueHasTime,
 	)
 	if err != nil {
 		return nil, err
@@ -374,39 +715,23 @@ func scanTodo(row *sql.Row) (*model.Todo, error) {
 		todo.CompletedAt = &completedAt.Time
 	}
 
-	todo.Completed = completed == 1
-
-	return &todo, nil
-}
-
-func scanTodoRows(rows *sql.Rows) (*model.Todo, error) {
-	var todo model.Todo
-	var tagsJSON string
-	var dueDate, completedAt sql.NullTime
-	var completed int
-
-	err := rows.Scan(
-		&todo.ID, &todo.Title, &todo.Description, &tagsJSON,
-		&dueDate, &todo.CreatedAt, &todo.UpdatedAt, &completedAt,
-		&completed, &todo.Priority,
-	)
-	if err != nil {
-		return nil, err
+	if deletedAt.Valid {
+		todo.DeletedAt = &deletedAt.Time
 	}
 
-	if err := json.Unmarshal([]byte(tagsJSON), &todo.Tags); err != nil {
-		todo.Tags = []string{}
+	if archivedAt.Valid {
+		todo.ArchivedAt = &archivedAt.Time
 	}
 
-	if dueDate.Valid {
-		todo.DueDate = &dueDate.Time
-	}
+	todo.Completed = completed == 1
 
-	if completedAt.Valid {
-		todo.CompletedAt = &completedAt.Time
+	if parentID.Valid {
+		todo.ParentID = &parentID.Int64
 	}
 
-	todo.Completed = completed == 1
+	if projectID.Valid {
+		todo.ProjectID = &projectID.Int64
+	}
 
 	return &todo, nil
 }
@@ -415,7 +740,38 @@ func nullableTime(t *time.Time) interface{} {
 	if t == nil {
 		return nil
 	}
-	return *t
+	return t.UTC()
+}
+
+func nullableInt(n *int64) interface{} {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
 }
 
 func boolToInt(b bool) int {
@@ -446,43 +802,9 @@ func ParseTags(tagStr string) []string {
 	return tags
 }
 
-// ParseDueDate parses a due date string like "2026-02-14" or "today" into a time.Time
+// ParseDueDate parses a due date string like "2026-02-14", "today" or
+// "tomorrow 9am" into a time.Time, see ParseDue
 func ParseDueDate(dateStr string) (*time.Time, error) {
-	if dateStr == "" {
-		return nil, nil
-	}
-
-	now := time.Now()
-
-	switch strings.ToLower(dateStr) {
-	case "today":
-		t := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
-		return &t, nil
-	case "tomorrow":
-		t := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location()).AddDate(0, 0, 1)
-		return &t, nil
-	case "next-week", "nextweek":
-		t := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location()).AddDate(0, 0, 7)
-		return &t, nil
-	default:
-		// Try parsing as date
-		formats := []string{
-			"2006-01-02",
-			"2006/01/02",
-			"01-02-2006",
-			"01/02/2006",
-			"Jan 2, 2006",
-			"January 2, 2006",
-		}
-
-		for _, format := range formats {
-			if t, err := time.Parse(format, dateStr); err == nil {
-				// Set to end of day
-				t = time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, now.Location())
-				return &t, nil
-			}
-		}
-
-		return nil, fmt.Errorf("invalid date format: %s", dateStr)
-	}
+	due, _, err := ParseDue(dateStr)
+	return due, err
 }
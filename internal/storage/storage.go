@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"context"
+	"iter"
 	"time"
 
 	"todo_cli/internal/model"
@@ -15,6 +17,7 @@ const (
 	SortByDueDate  SortField = "due"
 	SortByPriority SortField = "priority"
 	SortByTitle    SortField = "title"
+	SortByDeleted  SortField = "deleted"
 )
 
 // SortOrder defines ascending or descending order
@@ -38,27 +41,117 @@ const (
 
 // DueDateFilter defines a filter for due dates
 type DueDateFilter struct {
-	Type         DueDateFilterType
-	SpecificDate *time.Time
+	Type         DueDateFilterType `json:"type"`
+	SpecificDate *time.Time        `json:"specific_date,omitempty"`
 }
 
-// Filter defines criteria for filtering todos
+// Filter defines criteria for filtering todos. It is saved as JSON in views.
 type Filter struct {
-	Tags      []string
-	Completed *bool
-	DueDate   *DueDateFilter
-	SortBy    SortField
-	SortOrder SortOrder
-	Search    string
+	Tags            []string       `json:"tags,omitempty"`      // todos carrying all of these tags
+	TagsAny         []string       `json:"tags_any,omitempty"`  // todos carrying at least one of these tags
+	TagsNone        []string       `json:"tags_none,omitempty"` // todos carrying none of these tags
+	Completed       *bool          `json:"completed,omitempty"`
+	DueDate         *DueDateFilter `json:"due_date,omitempty"`
+	DueRange        *TimeRange     `json:"due_range,omitempty"`
+	NoDue           bool           `json:"no_due,omitempty"` // todos without a due date
+	CreatedRange    *TimeRange     `json:"created_range,omitempty"`
+	UpdatedRange    *TimeRange     `json:"updated_range,omitempty"`
+	CompletedRange  *TimeRange     `json:"completed_range,omitempty"`
+	PriorityMin     int            `json:"priority_min,omitempty"` // 1-5, 0 for no bound
+	PriorityMax     int            `json:"priority_max,omitempty"` // 1-5, 0 for no bound
+	SortBy          SortField      `json:"sort_by,omitempty"`
+	SortOrder       SortOrder      `json:"sort_order,omitempty"`
+	Sort            []SortKey      `json:"sort,omitempty"` // takes precedence over SortBy and SortOrder
+	Search          string         `json:"search,omitempty"`
+	ParentID        *int64         `json:"parent_id,omitempty"`        // only direct children of this todo
+	Project         string         `json:"project,omitempty"`          // project name
+	Blocked         *bool          `json:"blocked,omitempty"`          // true: has open blockers, false: ready to start
+	Query           string         `json:"query,omitempty"`            // filter expression, see package query
+	Trashed         bool           `json:"trashed,omitempty"`          // list the trash instead of live todos
+	IncludeArchived bool           `json:"include_archived,omitempty"` // also list archived todos
+
+	// Pagination is not saved with views
+	Limit   int   `json:"-"` // at most this many todos, 0 for all
+	Offset  int   `json:"-"` // skip this many todos first
+	AfterID int64 `json:"-"` // only todos after this one in the sort order, for keyset pagination
+}
+
+// View is a named, saved filter
+type View struct {
+	ID        int64
+	Name      string
+	Filter    Filter
+	CreatedAt time.Time
+}
+
+// TagCount is a tag and the number of todos carrying it
+type TagCount struct {
+	Tag   string
+	Count int
 }
 
+// ProjectStats holds per-project todo counts
+type ProjectStats struct {
+	Project   model.Project
+	Open      int
+	Overdue   int
+	Completed int
+}
+
+// OrphanPolicy controls what happens to the subtasks of a deleted todo
+type OrphanPolicy string
+
+const (
+	OrphanPromote OrphanPolicy = "promote" // children move up to the deleted todo's parent
+	OrphanCascade OrphanPolicy = "cascade" // children are deleted along with their parent
+	OrphanRefuse  OrphanPolicy = "refuse"  // deletion fails while the todo has children
+)
+
 // Storage defines the interface for todo persistence
 type Storage interface {
 	Create(todo *model.Todo) error
 	GetByID(id int64) (*model.Todo, error)
 	List(filter Filter) ([]model.Todo, error)
+	Stream(filter Filter) iter.Seq2[*model.Todo, error]
 	Update(todo *model.Todo) error
 	Delete(id int64) error
-	GetAllTags() ([]string, error)
+	DeleteWithPolicy(id int64, policy OrphanPolicy) error
+	Restore(id int64) ([]model.Todo, error)
+	PurgeTrash(before time.Time) (int, error)
+	Archive(completedBefore time.Time) (int, error)
+	AutoArchive(completedBefore time.Time) (int, error)
+	GetAllTags() ([]TagCount, error)
+	Search(query string, limit int) ([]SearchResult, error)
+
+	AddDependency(todoID, blockedByID int64) error
+	RemoveDependency(todoID, blockedByID int64) error
+	Blockers(todoID int64) ([]model.Todo, error)
+	Dependents(todoID int64) ([]model.Todo, error)
+
+	CreateProject(project *model.Project) error
+	GetProject(name string) (*model.Project, error)
+	ListProjects(includeArchived bool) ([]model.Project, error)
+	RenameProject(oldName, newName string) error
+	ArchiveProject(name string, archived bool) error
+	ProjectStats() ([]ProjectStats, error)
+
+	WithContext(ctx context.Context) Storage
+	WithTx(fn func(tx Storage) error) error
+	Batch(description string, fn func(tx Storage) error) error
+	Undo(n int) ([]Operation, error)
+	Redo(n int) ([]Operation, error)
+	Operations(limit int) ([]Operation, error)
+
+	History(todoID int64) ([]Event, error)
+	Events(since time.Time) ([]Event, error)
+
+	SaveView(name string, filter Filter) error
+	GetView(name string) (*View, error)
+	ListViews() ([]View, error)
+	DeleteView(name string) error
+
+	Setting(key string) (string, error)
+	SetSetting(key, value string) error
+
 	Close() error
 }
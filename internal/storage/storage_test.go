@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"todo_cli/internal/model"
+)
+
+// newTestStorage opens a fresh, fully migrated database
+func newTestStorage(t *testing.T) *SQLiteStorage {
+	t.Helper()
+	s, err := NewSQLiteStorageWithPath(filepath.Join(t.TempDir(), "todos.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// mustCreate creates a todo or fails the test
+func mustCreate(t *testing.T, s Storage, todo *model.Todo) *model.Todo {
+	t.Helper()
+	if err := s.Create(todo); err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}
+
+// mustGet loads a todo or fails the test
+func mustGet(t *testing.T, s Storage, id int64) *model.Todo {
+	t.Helper()
+	todo, err := s.GetByID(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return todo
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ageRe matches an age. Days, weeks, months and years are written as in
+// relative dates (see DateGrammar), so 2m is two months in both; minutes
+// are min.
+var ageRe = regexp.MustCompile(`^(\d+)(min|h|d|w|m|y)$`)
+
+// Age is a span of time back from now, see ParseAge
+type Age struct {
+	n    int
+	unit string
+}
+
+// ParseAge parses an age like 30min, 12h, 7d, 2w, 3m or 1y
+func ParseAge(s string) (Age, bool) {
+	m := ageRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return Age{}, false
+	}
+
+	n, _ := strconv.Atoi(m[1])
+	return Age{n: n, unit: m[2]}, true
+}
+
+// Before returns the time the age before t. Months and years are calendar
+// ones, as in addDateUnit.
+func (a Age) Before(t time.Time) time.Time {
+	switch a.unit {
+	case "min":
+		return t.Add(-time.Duration(a.n) * time.Minute)
+	case "h":
+		return t.Add(-time.Duration(a.n) * time.Hour)
+	default:
+		return addDateUnit(t, -a.n, a.unit)
+	}
+}
+
+// TimeRange limits a timestamp to a span between two dates (see
+// DateGrammar) or ages like 7d. An age counts back from now, so ranges of
+// due dates take dates such as +7d instead. The ends are resolved each time
+// the filter is used, so saved views stay relative; either may be left
+// empty.
+type TimeRange struct {
+	From string `json:"from,omitempty"` // inclusive
+	To   string `json:"to,omitempty"`   // inclusive; a day without a time counts whole
+}
+
+// ParseTimeRange parses "from..to", where either end may be left out
+func ParseTimeRange(s string) (*TimeRange, error) {
+	from, to, ok := strings.Cut(s, "..")
+	if !ok || strings.TrimSpace(from+to) == "" {
+		return nil, Errorf(ErrInvalid, "invalid range %q (use from..to, e.g. 2026-01-01..eom or 30d..7d)", s)
+	}
+	r := &TimeRange{From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+	if _, _, err := r.Bounds(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// String renders the range as ParseTimeRange reads it
+func (r TimeRange) String() string {
+	return r.From + ".." + r.To
+}
+
+// Bounds resolves the range against the current time. from is inclusive
+// and to exclusive; an open end is nil.
+func (r TimeRange) Bounds() (from, to *time.Time, err error) {
+	now := time.Now().In(time.Local)
+	if r.From != "" {
+		t, err := resolveBound(r.From, now, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &t
+	}
+	if r.To != "" {
+		t, err := resolveBound(r.To, now, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = &t
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, Errorf(ErrInvalid, "range %s ends before it starts", r)
+	}
+	return from, to, nil
+}
+
+// resolveBound resolves one end of a TimeRange. An age is that long before
+// now. A day without a time starts at midnight, or for the upper end, ends
+// at the next one.
+func resolveBound(expr string, now time.Time, upper bool) (time.Time, error) {
+	if age, ok := ParseAge(expr); ok {
+		return age.Before(now), nil
+	}
+
+	due, hasTime, err := ParseDue(expr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if hasTime {
+		if upper {
+			return due.Add(time.Minute), nil
+		}
+		return *due, nil
+	}
+	day := startOfDay(*due)
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// rangeCondition renders the bounds of r on column, or "" if r is open on
+// both ends
+func rangeCondition(column string, r *TimeRange) (string, []interface{}, error) {
+	from, to, err := r.Bounds()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var conds []string
+	var args []interface{}
+	if from != nil {
+		conds = append(conds, column+" >= ?")
+		args = append(args, from.UTC())
+	}
+	if to != nil {
+		conds = append(conds, column+" < ?")
+		args = append(args, to.UTC())
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	now := time.Date(2026, 3, 31, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		age  string
+		want time.Time
+	}{
+		{"30min", now.Add(-30 * time.Minute)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"7d", time.Date(2026, 3, 24, 15, 0, 0, 0, time.UTC)},
+		{"2w", time.Date(2026, 3, 17, 15, 0, 0, 0, time.UTC)},
+		// m is months, as in +1m, clamped to the end of the month
+		{"1m", time.Date(2026, 2, 28, 15, 0, 0, 0, time.UTC)},
+		{"1y", time.Date(2025, 3, 31, 15, 0, 0, 0, time.UTC)},
+		{" 3D ", time.Date(2026, 3, 28, 15, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		age, ok := ParseAge(tt.age)
+		if !ok {
+			t.Errorf("%q: not an age", tt.age)
+			continue
+		}
+		if got := age.Before(now); !got.Equal(tt.want) {
+			t.Errorf("%q: got %s, want %s", tt.age, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"", "7", "d", "-7d", "+7d", "7s", "2mins", "1.5h"} {
+		if _, ok := ParseAge(s); ok {
+			t.Errorf("%q: parsed as an age", s)
+		}
+	}
+}
+
+func TestResolveBoundAge(t *testing.T) {
+	now := time.Date(2026, 3, 31, 15, 0, 0, 0, time.UTC)
+
+	// An age counts back from now; a relative date counts from today
+	from, err := resolveBound("1m", now, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 2, 28, 15, 0, 0, 0, time.UTC); !from.Equal(want) {
+		t.Errorf("1m: got %s, want %s", from, want)
+	}
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// Restore brings a todo back from the trash along with the subtasks that
+// were trashed together with it, and returns the restored todos. A todo
+// whose parent is still in the trash is restored at the top level.
+func (s *SQLiteStorage) Restore(id int64) ([]model.Todo, error) {
+	todo, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !todo.IsDeleted() {
+		return nil, Errorf(ErrConflict, "todo #%d is not in the trash", id)
+	}
+
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	j, err := s.beginJournal(tx, fmt.Sprintf("restore #%d %q", id, todo.Title))
+	if err != nil {
+		return nil, err
+	}
+
+	// Subtasks deleted in the same operation share the parent's deleted_at
+	restored, err := selectTodos(tx, `
+		WITH RECURSIVE batch(id) AS (
+			SELECT ?
+			UNION
+			SELECT t.id FROM todos t JOIN batch b ON t.parent_id = b.id
+			WHERE t.deleted_at = (SELECT deleted_at FROM todos WHERE id = ?)
+		)
+		SELECT `+todoColumns+` FROM todos WHERE id IN batch ORDER BY id
+	`, id, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trashed subtasks: %w", err)
+	}
+
+	for i := range restored {
+		if err := j.capture(tx, restored[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if todo.ParentID != nil {
+		parent, err := loadTodo(tx, *todo.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.IsDeleted() {
+			if _, err := tx.Exec("UPDATE todos SET parent_id = NULL, version = version + 1 WHERE id = ?", id); err != nil {
+				return nil, fmt.Errorf("failed to detach todo from trashed parent: %w", err)
+			}
+		}
+	}
+
+	for i := range restored {
+		t := &restored[i]
+		if _, err := tx.Exec("UPDATE todos SET deleted_at = NULL, version = version + 1 WHERE id = ?", t.ID); err != nil {
+			return nil, fmt.Errorf("failed to restore todo: %w", err)
+		}
+		after, err := loadTodo(tx, t.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordChanges(tx, EventRestore, t.ID, t, after); err != nil {
+			return nil, err
+		}
+		restored[i] = *after
+	}
+
+	if err := j.finish(tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return restored, nil
+}
+
+// PurgeTrash permanently deletes the todos that were moved to the trash
+// before the given time and returns how many were removed. A purge can't be
+// undone: it is not journaled, and the undo log forgets the purged todos.
+func (s *SQLiteStorage) PurgeTrash(before time.Time) (int, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	purged, err := selectTodos(tx,
+		"SELECT "+todoColumns+" FROM todos WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY id",
+		before.UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load trash: %w", err)
+	}
+	if len(purged) == 0 {
+		return 0, nil
+	}
+
+	for i := range purged {
+		if _, err := tx.Exec("DELETE FROM todos WHERE id = ?", purged[i].ID); err != nil {
+			return 0, fmt.Errorf("failed to purge todo #%d: %w", purged[i].ID, err)
+		}
+		if err := recordChanges(tx, EventDelete, purged[i].ID, &purged[i], nil); err != nil {
+			return 0, err
+		}
+		if err := forgetUndo(tx, purged[i].ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit purge: %w", err)
+	}
+
+	return len(purged), nil
+}
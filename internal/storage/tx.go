@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// WithTx runs fn against a storage bound to a single transaction. Every
+// change fn makes through tx is committed together when it returns nil and
+// rolled back when it returns an error. It is a Batch named after the first
+// change made in it.
+func (s *SQLiteStorage) WithTx(fn func(tx Storage) error) error {
+	return s.Batch("", fn)
+}
+
+// Batch is WithTx for changes that are undone and redone as one operation
+// under the given description. Inside another Batch it joins the outer
+// transaction, naming the operation if it has no name yet.
+func (s *SQLiteStorage) Batch(description string, fn func(tx Storage) error) error {
+	if s.tx != nil {
+		if s.undoLabel == "" {
+			s.undoLabel = description
+		}
+		return fn(s)
+	}
+
+	sqlTx, err := s.db.BeginTx(s.context(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer sqlTx.Rollback()
+	tx := ctxConn{ctx: s.context(), q: sqlTx}
+
+	group, err := newUndoGroup(tx, "")
+	if err != nil {
+		return err
+	}
+	bound := &SQLiteStorage{db: s.db, tx: sqlTx, ctx: s.ctx, fts: s.fts, undoGroup: group, undoLabel: description}
+
+	if err := fn(bound); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		"DELETE FROM undo_groups WHERE id = ? AND NOT EXISTS (SELECT 1 FROM undo_entries WHERE group_id = ?)",
+		group, group,
+	); err != nil {
+		return fmt.Errorf("failed to clean up undo log: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE undo_groups SET description = ? WHERE id = ?", bound.undoLabel, group); err != nil {
+		return fmt.Errorf("failed to update undo log: %w", err)
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// conn returns the transaction the storage is bound to, or the database
+func (s *SQLiteStorage) conn() dbtx {
+	if s.tx != nil {
+		return ctxConn{ctx: s.context(), q: s.tx}
+	}
+	return ctxConn{ctx: s.context(), q: s.db}
+}
+
+// txn is a transaction for a single storage call. Inside a Batch it is a
+// savepoint, so a failed call leaves the rest of the transaction intact.
+type txn struct {
+	ctxConn
+	tx        *sql.Tx
+	savepoint bool
+	done      bool
+}
+
+func (s *SQLiteStorage) begin() (*txn, error) {
+	if s.tx == nil {
+		tx, err := s.db.BeginTx(s.context(), nil)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{ctxConn: ctxConn{ctx: s.context(), q: tx}, tx: tx}, nil
+	}
+
+	t := &txn{ctxConn: ctxConn{ctx: s.context(), q: s.tx}, tx: s.tx, savepoint: true}
+	if _, err := t.Exec("SAVEPOINT storage_call"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Commit commits the transaction or releases the savepoint
+func (t *txn) Commit() error {
+	if !t.savepoint {
+		return t.tx.Commit()
+	}
+	t.done = true
+	_, err := t.Exec("RELEASE storage_call")
+	return err
+}
+
+// Rollback rolls back the transaction or the savepoint. Like sql.Tx it may
+// be deferred: after Commit it does nothing.
+func (t *txn) Rollback() error {
+	if !t.savepoint {
+		return t.tx.Rollback()
+	}
+	if t.done {
+		return nil
+	}
+	t.done = true
+	if _, err := t.Exec("ROLLBACK TO storage_call"); err != nil {
+		return err
+	}
+	_, err := t.Exec("RELEASE storage_call")
+	return err
+}
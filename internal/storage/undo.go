@@ -0,0 +1,521 @@
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// undoLimit is how many operations the undo log keeps
+const undoLimit = 200
+
+// Operation is an undoable change, possibly spanning several todos
+type Operation struct {
+	ID          int64
+	Description string
+	Undone      bool
+	At          time.Time
+}
+
+// snapshot is the full state of a todo as stored in the undo log
+type snapshot struct {
+	Todo      model.Todo `json:"todo"`
+	BlockedBy []int64    `json:"blocked_by,omitempty"`
+	Blocks    []int64    `json:"blocks,omitempty"`
+}
+
+func loadSnapshot(q dbtx, id int64) (*snapshot, error) {
+	var exists bool
+	if err := q.QueryRow("SELECT EXISTS(SELECT 1 FROM todos WHERE id = ?)", id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	todo, err := loadTodo(q, id)
+	if err != nil {
+		return nil, err
+	}
+	snap := &snapshot{Todo: *todo}
+
+	if snap.BlockedBy, err = selectIDs(q, "SELECT blocked_by_id FROM todo_dependencies WHERE todo_id = ? ORDER BY 1", id); err != nil {
+		return nil, err
+	}
+	if snap.Blocks, err = selectIDs(q, "SELECT todo_id FROM todo_dependencies WHERE blocked_by_id = ? ORDER BY 1", id); err != nil {
+		return nil, err
+	}
+
+	return snap, nil
+}
+
+func selectIDs(q dbtx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan ID: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// journal collects the before and after states of the todos touched by one
+// storage call and writes them to the undo log
+type journal struct {
+	group  int64
+	ids    []int64
+	before map[int64]*snapshot
+}
+
+// beginJournal starts logging a change, as part of the operation of the
+// enclosing Batch if any
+func (s *SQLiteStorage) beginJournal(tx dbtx, description string) (*journal, error) {
+	if s.tx != nil && s.undoLabel == "" {
+		s.undoLabel = description
+	}
+	group := s.undoGroup
+	if group == 0 {
+		var err error
+		if group, err = newUndoGroup(tx, description); err != nil {
+			return nil, err
+		}
+	}
+	return &journal{group: group, before: make(map[int64]*snapshot)}, nil
+}
+
+// capture records the current state of todos about to change
+func (j *journal) capture(q dbtx, ids ...int64) error {
+	for _, id := range ids {
+		if _, ok := j.before[id]; ok {
+			continue
+		}
+		snap, err := loadSnapshot(q, id)
+		if err != nil {
+			return err
+		}
+		j.before[id] = snap
+		j.ids = append(j.ids, id)
+	}
+	return nil
+}
+
+// created records a todo that did not exist before
+func (j *journal) created(id int64) {
+	j.before[id] = nil
+	j.ids = append(j.ids, id)
+}
+
+// finish writes an undo entry for every captured todo that changed
+func (j *journal) finish(q dbtx) error {
+	for _, id := range j.ids {
+		after, err := loadSnapshot(q, id)
+		if err != nil {
+			return err
+		}
+
+		before, err := encodeSnapshot(j.before[id])
+		if err != nil {
+			return err
+		}
+		current, err := encodeSnapshot(after)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(before, current) {
+			continue
+		}
+
+		if _, err := q.Exec(
+			"INSERT INTO undo_entries (group_id, todo_id, before, after) VALUES (?, ?, ?, ?)",
+			j.group, id, nullableJSON(before), nullableJSON(current),
+		); err != nil {
+			return fmt.Errorf("failed to write undo log: %w", err)
+		}
+	}
+	return nil
+}
+
+func encodeSnapshot(snap *snapshot) ([]byte, error) {
+	if snap == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode undo state: %w", err)
+	}
+	return data, nil
+}
+
+func nullableJSON(data []byte) interface{} {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// newUndoGroup opens a new operation. Anything that was undone can no
+// longer be redone, and the oldest operations beyond undoLimit are dropped.
+func newUndoGroup(q dbtx, description string) (int64, error) {
+	if _, err := q.Exec("DELETE FROM undo_groups WHERE undone = 1"); err != nil {
+		return 0, fmt.Errorf("failed to clear redo log: %w", err)
+	}
+	if _, err := q.Exec(
+		"DELETE FROM undo_groups WHERE id <= (SELECT id FROM undo_groups ORDER BY id DESC LIMIT 1 OFFSET ?)",
+		undoLimit-1,
+	); err != nil {
+		return 0, fmt.Errorf("failed to trim undo log: %w", err)
+	}
+
+	result, err := q.Exec(
+		"INSERT INTO undo_groups (description, created_at) VALUES (?, ?)",
+		description, time.Now().UTC(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write undo log: %w", err)
+	}
+	return result.LastInsertId()
+}
+
+// forgetUndo removes a todo from the undo log, so its contents are gone and
+// undo can't bring it back. Operations left with nothing to replay are
+// dropped.
+func forgetUndo(q dbtx, id int64) error {
+	groups, err := selectIDs(q, "SELECT DISTINCT group_id FROM undo_entries WHERE todo_id = ?", id)
+	if err != nil {
+		return err
+	}
+	if _, err := q.Exec("DELETE FROM undo_entries WHERE todo_id = ?", id); err != nil {
+		return fmt.Errorf("failed to clear undo log of #%d: %w", id, err)
+	}
+	for _, group := range groups {
+		if _, err := q.Exec(
+			"DELETE FROM undo_groups WHERE id = ? AND NOT EXISTS (SELECT 1 FROM undo_entries WHERE group_id = ?)",
+			group, group,
+		); err != nil {
+			return fmt.Errorf("failed to clear undo log of #%d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// Undo reverts the last n operations, newest first, and returns them
+func (s *SQLiteStorage) Undo(n int) ([]Operation, error) {
+	return s.replay(n, true)
+}
+
+// Redo reapplies the last n undone operations and returns them
+func (s *SQLiteStorage) Redo(n int) ([]Operation, error) {
+	return s.replay(n, false)
+}
+
+// Operations returns the most recent operations in the undo log, newest first
+func (s *SQLiteStorage) Operations(limit int) ([]Operation, error) {
+	rows, err := s.conn().Query(
+		"SELECT id, description, undone, created_at FROM undo_groups ORDER BY id DESC LIMIT ?",
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query undo log: %w", err)
+	}
+	defer rows.Close()
+
+	var ops []Operation
+	for rows.Next() {
+		var op Operation
+		if err := rows.Scan(&op.ID, &op.Description, &op.Undone, &op.At); err != nil {
+			return nil, fmt.Errorf("failed to scan undo log: %w", err)
+		}
+		ops = append(ops, op)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating undo log: %w", err)
+	}
+
+	return ops, nil
+}
+
+func (s *SQLiteStorage) replay(n int, undo bool) ([]Operation, error) {
+	tx, err := s.begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rows are restored one at a time, so a subtask may briefly point at a
+	// parent that is restored later in the same operation
+	if _, err := tx.Exec("PRAGMA defer_foreign_keys = ON"); err != nil {
+		return nil, fmt.Errorf("failed to defer foreign keys: %w", err)
+	}
+
+	next := "SELECT id, description, undone, created_at FROM undo_groups WHERE undone = 0 ORDER BY id DESC LIMIT 1"
+	entryOrder := "DESC"
+	if !undo {
+		next = "SELECT id, description, undone, created_at FROM undo_groups WHERE undone = 1 ORDER BY id LIMIT 1"
+		entryOrder = "ASC"
+	}
+
+	var ops []Operation
+	for len(ops) < n {
+		var op Operation
+		err := tx.QueryRow(next).Scan(&op.ID, &op.Description, &op.Undone, &op.At)
+		if err == sql.ErrNoRows {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read undo log: %w", err)
+		}
+
+		if err := applyUndoEntries(tx, op.ID, entryOrder, undo); err != nil {
+			return nil, fmt.Errorf("failed to replay %q: %w", op.Description, err)
+		}
+
+		op.Undone = undo
+		if _, err := tx.Exec("UPDATE undo_groups SET undone = ? WHERE id = ?", boolToInt(undo), op.ID); err != nil {
+			return nil, fmt.Errorf("failed to update undo log: %w", err)
+		}
+		ops = append(ops, op)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit undo: %w", err)
+	}
+
+	return ops, nil
+}
+
+// applyUndoEntries restores every todo of an operation to its before (undo)
+// or after (redo) state. Dependencies are restored once all rows exist.
+func applyUndoEntries(tx dbtx, group int64, order string, undo bool) error {
+	column, other := "after", "before"
+	if undo {
+		column, other = "before", "after"
+	}
+
+	rows, err := tx.Query("SELECT todo_id, "+column+", "+other+" FROM undo_entries WHERE group_id = ? ORDER BY id "+order, group)
+	if err != nil {
+		return fmt.Errorf("failed to read undo entries: %w", err)
+	}
+
+	type target struct {
+		id       int64
+		snap     *snapshot // state to restore
+		expected *snapshot // state the todo should be in now
+	}
+	var targets []target
+	for rows.Next() {
+		var t target
+		var data, expected sql.NullString
+		if err := rows.Scan(&t.id, &data, &expected); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan undo entry: %w", err)
+		}
+		if t.snap, err = decodeSnapshot(data); err != nil {
+			rows.Close()
+			return fmt.Errorf("invalid undo entry for #%d: %w", t.id, err)
+		}
+		if t.expected, err = decodeSnapshot(expected); err != nil {
+			rows.Close()
+			return fmt.Errorf("invalid undo entry for #%d: %w", t.id, err)
+		}
+		targets = append(targets, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating undo entries: %w", err)
+	}
+
+	for _, t := range targets {
+		if err := restoreSnapshot(tx, t.id, t.snap, t.expected); err != nil {
+			return err
+		}
+	}
+	for _, t := range targets {
+		if t.snap == nil {
+			continue
+		}
+		if err := restoreDependencies(tx, t.id, t.snap); err != nil {
+			return err
+		}
+	}
+
+	// A restored subtask may point at a parent that was purged since
+	for _, t := range targets {
+		if t.snap == nil || t.snap.Todo.ParentID == nil {
+			continue
+		}
+		if _, err := tx.Exec(
+			"UPDATE todos SET parent_id = NULL WHERE id = ? AND parent_id NOT IN (SELECT id FROM todos)", t.id,
+		); err != nil {
+			return fmt.Errorf("failed to detach #%d from its purged parent: %w", t.id, err)
+		}
+	}
+
+	return nil
+}
+
+func decodeSnapshot(data sql.NullString) (*snapshot, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	snap := &snapshot{}
+	if err := json.Unmarshal([]byte(data.String), snap); err != nil {
+		return nil, err
+	}
+	return snap, nil
+}
+
+// restoreSnapshot puts a todo row back into the given state, deleting it
+// when snap is nil, and records the change in the history. It fails with a
+// ConflictError if the todo is no longer in the expected state, i.e. it was
+// changed since the operation without going through the undo log.
+func restoreSnapshot(tx dbtx, id int64, snap, expected *snapshot) error {
+	current, err := loadSnapshot(tx, id)
+	if err != nil {
+		return err
+	}
+	if current != nil && expected != nil {
+		want := expected.Todo
+		if want.ParentID != nil {
+			// A purged parent leaves its subtasks at the top level
+			var exists bool
+			if err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM todos WHERE id = ?)", *want.ParentID).Scan(&exists); err != nil {
+				return fmt.Errorf("failed to get todo: %w", err)
+			}
+			if !exists {
+				want.ParentID = nil
+			}
+		}
+		if !sameRow(&current.Todo, &want) {
+			return &ConflictError{Current: &current.Todo}
+		}
+	}
+
+	var before, after *model.Todo
+	op := EventUpdate
+	if current != nil {
+		before = &current.Todo
+	} else {
+		op = EventCreate
+	}
+
+	if snap == nil {
+		if current == nil {
+			return nil
+		}
+		if _, err := tx.Exec("DELETE FROM todos WHERE id = ?", id); err != nil {
+			return fmt.Errorf("failed to delete todo #%d: %w", id, err)
+		}
+		return recordChanges(tx, EventDelete, id, before, nil)
+	}
+
+	t := snap.Todo
+	_, err = tx.Exec(`
+		INSERT INTO todos (id, title, description, due_date, due_has_time, created_at, updated_at, completed_at, completed, priority, recurrence, parent_id, project_id, deleted_at, archived_at, version)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			title = excluded.title, description = excluded.description,
+			due_date = excluded.due_date, due_has_time = excluded.due_has_time,
+			created_at = excluded.created_at, updated_at = excluded.updated_at,
+			completed_at = excluded.completed_at, completed = excluded.completed,
+			priority = excluded.priority, recurrence = excluded.recurrence,
+			parent_id = excluded.parent_id, project_id = excluded.project_id,
+			deleted_at = excluded.deleted_at, archived_at = excluded.archived_at,
+			version = todos.version + 1
+	`, id, t.Title, t.Description, nullableTime(t.DueDate), boolToInt(t.DueHasTime), t.CreatedAt.UTC(), t.UpdatedAt.UTC(),
+		nullableTime(t.CompletedAt), boolToInt(t.Completed), t.Priority, t.Recurrence,
+		nullableInt(t.ParentID), nullableInt(t.ProjectID), nullableTime(t.DeletedAt), nullableTime(t.ArchivedAt),
+		t.Version+1)
+	if err != nil {
+		return fmt.Errorf("failed to restore todo #%d: %w", id, err)
+	}
+
+	if err := setTags(tx, id, t.Tags); err != nil {
+		return err
+	}
+
+	if after, err = loadTodo(tx, id); err != nil {
+		return err
+	}
+	return recordChanges(tx, op, id, before, after)
+}
+
+// sameRow reports whether two states of a todo have the same stored
+// fields. The version is left out, as every restore bumps it, and so are the
+// counts and names looked up from other rows.
+func sameRow(a, b *model.Todo) bool {
+	return a.Title == b.Title &&
+		a.Description == b.Description &&
+		slices.Equal(a.Tags, b.Tags) &&
+		sameTime(a.DueDate, b.DueDate) &&
+		a.DueHasTime == b.DueHasTime &&
+		a.CreatedAt.Equal(b.CreatedAt) &&
+		a.UpdatedAt.Equal(b.UpdatedAt) &&
+		a.Completed == b.Completed &&
+		sameTime(a.CompletedAt, b.CompletedAt) &&
+		a.Priority == b.Priority &&
+		a.Recurrence == b.Recurrence &&
+		sameID(a.ParentID, b.ParentID) &&
+		sameID(a.ProjectID, b.ProjectID) &&
+		sameTime(a.DeletedAt, b.DeletedAt) &&
+		sameTime(a.ArchivedAt, b.ArchivedAt)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameID(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// restoreDependencies resets a todo's edges to the snapshot, skipping todos
+// that no longer exist
+func restoreDependencies(tx dbtx, id int64, snap *snapshot) error {
+	if _, err := tx.Exec("DELETE FROM todo_dependencies WHERE todo_id = ? OR blocked_by_id = ?", id, id); err != nil {
+		return fmt.Errorf("failed to reset dependencies of #%d: %w", id, err)
+	}
+
+	insert := func(todoID, blockedByID int64) error {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO todo_dependencies (todo_id, blocked_by_id)
+			SELECT ?, ? WHERE EXISTS (SELECT 1 FROM todos WHERE id = ?) AND EXISTS (SELECT 1 FROM todos WHERE id = ?)
+		`, todoID, blockedByID, todoID, blockedByID)
+		if err != nil {
+			return fmt.Errorf("failed to restore dependencies of #%d: %w", id, err)
+		}
+		return nil
+	}
+
+	for _, blocker := range snap.BlockedBy {
+		if err := insert(id, blocker); err != nil {
+			return err
+		}
+	}
+	for _, dependent := range snap.Blocks {
+		if err := insert(dependent, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
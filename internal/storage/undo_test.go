@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"todo_cli/internal/model"
+)
+
+// mustReplay undoes (or redoes) n operations and checks how many were
+func mustReplay(t *testing.T, s *SQLiteStorage, undo bool, n, want int) {
+	t.Helper()
+	replay := s.Redo
+	if undo {
+		replay = s.Undo
+	}
+	ops, err := replay(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != want {
+		t.Fatalf("replayed %d operations, want %d", len(ops), want)
+	}
+}
+
+func TestUndoRedoCreate(t *testing.T) {
+	s := newTestStorage(t)
+	todo := mustCreate(t, s, &model.Todo{Title: "Write tests", Tags: []string{"dev"}})
+
+	mustReplay(t, s, true, 1, 1)
+	if _, err := s.GetByID(todo.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("after undo: got %v, want ErrNotFound", err)
+	}
+
+	mustReplay(t, s, false, 1, 1)
+	got := mustGet(t, s, todo.ID)
+	if got.Title != "Write tests" || !slices.Equal(got.Tags, []string{"dev"}) {
+		t.Errorf("after redo: got %+v", got)
+	}
+
+	// Nothing left to redo; undo works again
+	mustReplay(t, s, false, 1, 0)
+	mustReplay(t, s, true, 1, 1)
+	mustReplay(t, s, true, 1, 0)
+}
+
+func TestUndoRedoUpdate(t *testing.T) {
+	s := newTestStorage(t)
+	due := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	todo := mustCreate(t, s, &model.Todo{Title: "Draft", Tags: []string{"a"}, Priority: 2})
+
+	todo.Title = "Final"
+	todo.Tags = []string{"b", "a"}
+	todo.DueDate = &due
+	todo.DueHasTime = true
+	if err := s.Update(todo); err != nil {
+		t.Fatal(err)
+	}
+
+	mustReplay(t, s, true, 1, 1)
+	got := mustGet(t, s, todo.ID)
+	if got.Title != "Draft" || !slices.Equal(got.Tags, []string{"a"}) || got.DueDate != nil || got.DueHasTime {
+		t.Errorf("after undo: got %+v", got)
+	}
+	if got.Version <= todo.Version {
+		t.Errorf("undo kept version %d, want it bumped past %d", got.Version, todo.Version)
+	}
+
+	mustReplay(t, s, false, 1, 1)
+	got = mustGet(t, s, todo.ID)
+	if got.Title != "Final" || !slices.Equal(got.Tags, []string{"b", "a"}) || !got.DueDate.Equal(due) || !got.DueHasTime {
+		t.Errorf("after redo: got %+v", got)
+	}
+
+	// Undo twice goes back to before the todo existed
+	mustReplay(t, s, true, 5, 2)
+	if _, err := s.GetByID(todo.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+}
+
+func TestUndoCascadedDelete(t *testing.T) {
+	s := newTestStorage(t)
+	parent := mustCreate(t, s, &model.Todo{Title: "Parent"})
+	child := mustCreate(t, s, &model.Todo{Title: "Child", ParentID: &parent.ID})
+	grandchild := mustCreate(t, s, &model.Todo{Title: "Grandchild", ParentID: &child.ID})
+
+	if err := s.DeleteWithPolicy(parent.ID, OrphanCascade); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{parent.ID, child.ID, grandchild.ID} {
+		if !mustGet(t, s, id).IsDeleted() {
+			t.Fatalf("#%d was not deleted", id)
+		}
+	}
+
+	mustReplay(t, s, true, 1, 1)
+	for _, id := range []int64{parent.ID, child.ID, grandchild.ID} {
+		if mustGet(t, s, id).IsDeleted() {
+			t.Errorf("#%d is still deleted after undo", id)
+		}
+	}
+	if got := mustGet(t, s, grandchild.ID).ParentID; got == nil || *got != child.ID {
+		t.Errorf("grandchild parent: got %v, want #%d", got, child.ID)
+	}
+	if got := mustGet(t, s, parent.ID); got.SubtaskTotal != 1 {
+		t.Errorf("parent has %d subtasks after undo, want 1", got.SubtaskTotal)
+	}
+
+	mustReplay(t, s, false, 1, 1)
+	for _, id := range []int64{parent.ID, child.ID, grandchild.ID} {
+		if !mustGet(t, s, id).IsDeleted() {
+			t.Errorf("#%d is not deleted after redo", id)
+		}
+	}
+}
+
+func TestUndoSubtreeCreatedInBatch(t *testing.T) {
+	s := newTestStorage(t)
+
+	// Undo deletes the rows of the subtree one at a time, and redo creates
+	// them again, with the foreign keys checked at commit
+	var ids []int64
+	err := s.Batch("add subtree", func(tx Storage) error {
+		parent := mustCreate(t, tx, &model.Todo{Title: "Parent"})
+		child := mustCreate(t, tx, &model.Todo{Title: "Child", ParentID: &parent.ID})
+		leaf := mustCreate(t, tx, &model.Todo{Title: "Leaf", ParentID: &child.ID})
+		ids = []int64{parent.ID, child.ID, leaf.ID}
+
+		// Move the leaf to the top, so it no longer follows its parent
+		leaf.ParentID = &parent.ID
+		return tx.Update(leaf)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustReplay(t, s, true, 1, 1)
+	for _, id := range ids {
+		if _, err := s.GetByID(id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("#%d after undo: got %v, want ErrNotFound", id, err)
+		}
+	}
+
+	mustReplay(t, s, false, 1, 1)
+	leaf := mustGet(t, s, ids[2])
+	if leaf.ParentID == nil || *leaf.ParentID != ids[0] {
+		t.Errorf("leaf parent after redo: got %v, want #%d", leaf.ParentID, ids[0])
+	}
+}
+
+func TestUndoBatch(t *testing.T) {
+	s := newTestStorage(t)
+	existing := mustCreate(t, s, &model.Todo{Title: "Existing"})
+
+	err := s.Batch("bulk change", func(tx Storage) error {
+		for _, title := range []string{"One", "Two", "Three"} {
+			mustCreate(t, tx, &model.Todo{Title: title})
+		}
+		todo := mustGet(t, tx, existing.ID)
+		todo.Completed = true
+		return tx.Update(todo)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops, err := s.Operations(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 || ops[0].Description != "bulk change" {
+		t.Fatalf("got operations %+v, want the batch and the first create", ops)
+	}
+
+	mustReplay(t, s, true, 1, 1)
+	todos, err := s.List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 1 || todos[0].Completed {
+		t.Errorf("after undo: got %+v, want only the open existing todo", todos)
+	}
+
+	mustReplay(t, s, false, 1, 1)
+	if todos, _ = s.List(Filter{}); len(todos) != 4 {
+		t.Errorf("after redo: got %d todos, want 4", len(todos))
+	}
+	if !mustGet(t, s, existing.ID).Completed {
+		t.Error("after redo: existing todo is not completed")
+	}
+
+	// A failed batch leaves nothing to undo
+	err = s.Batch("failing", func(tx Storage) error {
+		mustCreate(t, tx, &model.Todo{Title: "Rolled back"})
+		return errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected the batch to fail")
+	}
+	if ops, _ = s.Operations(10); ops[0].Description != "bulk change" {
+		t.Errorf("got newest operation %q after a failed batch", ops[0].Description)
+	}
+}
+
+func TestUndoDependencies(t *testing.T) {
+	s := newTestStorage(t)
+	a := mustCreate(t, s, &model.Todo{Title: "A"})
+	b := mustCreate(t, s, &model.Todo{Title: "B"})
+
+	blockers := func() []int64 {
+		t.Helper()
+		todos, err := s.Blockers(a.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, todo := range todos {
+			ids = append(ids, todo.ID)
+		}
+		return ids
+	}
+
+	if err := s.AddDependency(a.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	mustReplay(t, s, true, 1, 1)
+	if got := blockers(); len(got) != 0 {
+		t.Errorf("after undo: got blockers %v, want none", got)
+	}
+	mustReplay(t, s, false, 1, 1)
+	if got := blockers(); !slices.Equal(got, []int64{b.ID}) {
+		t.Errorf("after redo: got blockers %v, want [%d]", got, b.ID)
+	}
+
+	if err := s.RemoveDependency(a.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	mustReplay(t, s, true, 1, 1)
+	if got := blockers(); !slices.Equal(got, []int64{b.ID}) {
+		t.Errorf("after undoing the removal: got blockers %v, want [%d]", got, b.ID)
+	}
+
+	// Undoing the creation of the blocker drops the edge with it, and
+	// redoing it brings both back
+	mustReplay(t, s, true, 2, 2)
+	if got := blockers(); len(got) != 0 {
+		t.Errorf("without B: got blockers %v, want none", got)
+	}
+	mustReplay(t, s, false, 2, 2)
+	if got := blockers(); !slices.Equal(got, []int64{b.ID}) {
+		t.Errorf("after redoing B: got blockers %v, want [%d]", got, b.ID)
+	}
+}
+
+func TestNewChangeClearsRedo(t *testing.T) {
+	s := newTestStorage(t)
+	mustCreate(t, s, &model.Todo{Title: "First"})
+	mustCreate(t, s, &model.Todo{Title: "Second"})
+
+	mustReplay(t, s, true, 1, 1)
+	mustCreate(t, s, &model.Todo{Title: "Third"})
+	mustReplay(t, s, false, 1, 0)
+
+	todos, err := s.List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 2 {
+		t.Errorf("got %d todos, want First and Third", len(todos))
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	s := newTestStorage(t)
+	for range undoLimit + 5 {
+		mustCreate(t, s, &model.Todo{Title: "Todo"})
+	}
+
+	ops, err := s.Operations(undoLimit * 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != undoLimit {
+		t.Fatalf("kept %d operations, want %d", len(ops), undoLimit)
+	}
+
+	// The entries of dropped operations go with them
+	var entries int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM undo_entries").Scan(&entries); err != nil {
+		t.Fatal(err)
+	}
+	if entries != undoLimit {
+		t.Errorf("kept %d undo entries, want %d", entries, undoLimit)
+	}
+
+	mustReplay(t, s, true, undoLimit*2, undoLimit)
+	todos, err := s.List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 5 {
+		t.Errorf("got %d todos after undoing everything, want the 5 beyond the limit", len(todos))
+	}
+}
+
+func TestUndoConflict(t *testing.T) {
+	s := newTestStorage(t)
+	todo := mustCreate(t, s, &model.Todo{Title: "Report"})
+	if _, err := Complete(s, todo, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Auto-archiving changes the todo outside the undo log
+	if _, err := s.AutoArchive(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := s.Undo(1)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrConflict) {
+		t.Fatalf("got %v, want a ConflictError", err)
+	}
+	if conflict.Current.ID != todo.ID || conflict.Current.ArchivedAt == nil {
+		t.Errorf("conflict carries %+v, want the archived todo", conflict.Current)
+	}
+
+	// Nothing was undone
+	got := mustGet(t, s, todo.ID)
+	if !got.Completed || got.ArchivedAt == nil {
+		t.Errorf("undo changed the todo: %+v", got)
+	}
+	if ops, _ := s.Operations(1); ops[0].Undone {
+		t.Error("the operation was marked undone")
+	}
+}
+
+func TestPurgeIsNotUndoable(t *testing.T) {
+	s := newTestStorage(t)
+	keep := mustCreate(t, s, &model.Todo{Title: "Keep"})
+	gone := mustCreate(t, s, &model.Todo{Title: "Secret"})
+	child := mustCreate(t, s, &model.Todo{Title: "Child", ParentID: &gone.ID})
+
+	// Move the child out first, so only its old parent is purged
+	child.ParentID = nil
+	if err := s.Update(child); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := s.PurgeTrash(time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("purged %d todos (%v), want 1", n, err)
+	}
+
+	var n int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM undo_entries WHERE todo_id = ?", gone.ID).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("the undo log keeps %d entries of the purged todo", n)
+	}
+
+	// Undoing everything brings back neither the purged todo nor its
+	// children's link to it
+	if _, err := s.Undo(undoLimit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetByID(gone.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("purged todo: got %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetByID(keep.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("undo skipped the creation of #%d: %v", keep.ID, err)
+	}
+}
@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SaveView stores a filter under a name, replacing any view with that name
+func (s *SQLiteStorage) SaveView(name string, filter Filter) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Errorf(ErrInvalid, "view name is required")
+	}
+	if strings.ContainsAny(name, " \t@") {
+		return Errorf(ErrInvalid, "view name %q must not contain spaces or '@'", name)
+	}
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return fmt.Errorf("failed to encode filter: %w", err)
+	}
+
+	_, err = s.conn().Exec(`
+		INSERT INTO views (name, filter, created_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET filter = excluded.filter
+	`, name, string(data), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save view: %w", err)
+	}
+
+	return nil
+}
+
+// GetView retrieves a view by name (case-insensitive)
+func (s *SQLiteStorage) GetView(name string) (*View, error) {
+	row := s.conn().QueryRow("SELECT id, name, filter, created_at FROM views WHERE name = ?", name)
+
+	view, err := scanView(row)
+	if err == sql.ErrNoRows {
+		return nil, Errorf(ErrNotFound, "view %q not found", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view: %w", err)
+	}
+
+	return view, nil
+}
+
+// ListViews returns all views ordered by creation, which is their tab order
+func (s *SQLiteStorage) ListViews() ([]View, error) {
+	rows, err := s.conn().Query("SELECT id, name, filter, created_at FROM views ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []View
+	for rows.Next() {
+		view, err := scanView(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan view: %w", err)
+		}
+		views = append(views, *view)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating views: %w", err)
+	}
+
+	return views, nil
+}
+
+// DeleteView removes a view by name
+func (s *SQLiteStorage) DeleteView(name string) error {
+	result, err := s.conn().Exec("DELETE FROM views WHERE name = ?", name)
+	if err != nil {
+		return fmt.Errorf("failed to delete view: %w", err)
+	}
+
+	return expectRow(result, fmt.Sprintf("view %q not found", name))
+}
+
+func scanView(row rowScanner) (*View, error) {
+	var view View
+	var data string
+
+	if err := row.Scan(&view.ID, &view.Name, &data, &view.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &view.Filter); err != nil {
+		return nil, fmt.Errorf("view %q has an invalid filter: %w", view.Name, err)
+	}
+
+	return &view, nil
+}
@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adrg/xdg"
+)
+
+// DefaultWorkspace is the workspace kept in the database at DefaultDBPath
+const DefaultWorkspace = "default"
+
+var workspaceName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// CheckWorkspaceName rejects workspace names that can't be used as file names
+func CheckWorkspaceName(name string) error {
+	if !workspaceName.MatchString(name) {
+		return Errorf(ErrInvalid, "invalid workspace name %q (use letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+// WorkspacePath returns the database file of a workspace. Each workspace is
+// its own database; all but the default one live in a workspaces directory
+// next to the default database.
+func WorkspacePath(name string) (string, error) {
+	if name == "" || name == DefaultWorkspace {
+		return getDBPath()
+	}
+	if err := CheckWorkspaceName(name); err != nil {
+		return "", err
+	}
+	return filepath.Join(workspaceDir(), name+".db"), nil
+}
+
+// Workspaces returns the names of all workspaces, the default one first
+func Workspaces() ([]string, error) {
+	entries, err := os.ReadDir(workspaceDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".db")
+		if ok && !entry.IsDir() && workspaceName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return append([]string{DefaultWorkspace}, names...), nil
+}
+
+// WorkspaceExists reports whether a workspace was created
+func WorkspaceExists(name string) (bool, error) {
+	if name == DefaultWorkspace {
+		return true, nil
+	}
+	path, err := WorkspacePath(name)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check workspace %s: %w", name, err)
+	}
+	return true, nil
+}
+
+func workspaceDir() string {
+	return filepath.Join(xdg.DataHome, "todocli", "workspaces")
+}
@@ -1,9 +1,13 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"todo_cli/internal/model"
 	"todo_cli/internal/storage"
 )
 
@@ -17,6 +21,8 @@ const (
 	ViewEdit
 	ViewSearch
 	ViewTagFilter
+	ViewTrash
+	ViewQuickAdd
 )
 
 // App is the main TUI application model
@@ -26,18 +32,68 @@ type App struct {
 	list     *ListView
 	detail   *DetailView
 	input    *InputView
+	quick    *QuickAddView
 	width    int
 	height   int
 	err      error
+	status   string
+	conflict *conflictMsg // pending reload-or-force prompt
 	quitting bool
+
+	workspaces    []string
+	openWorkspace func(name string) (storage.Storage, error)
+	owned         storage.Storage // workspace opened by the TUI, closed by Close
 }
 
 // Message types
 type errMsg struct{ err error }
 type todosLoadedMsg struct{}
 type todoCreatedMsg struct{}
+type quickAddedMsg struct{ todo *model.Todo }
 type todoUpdatedMsg struct{}
 type todoDeletedMsg struct{}
+type todoRestoredMsg struct{ todos []model.Todo }
+type historyLoadedMsg struct{}
+type undoneMsg struct {
+	ops  []storage.Operation
+	redo bool
+}
+
+// conflictMsg reports a write that lost against a change made elsewhere,
+// e.g. by todo edit in another terminal
+type conflictMsg struct {
+	current *model.Todo    // the todo as it is stored now
+	force   func() tea.Msg // retries the write on top of current
+}
+
+// saveTodo returns a command running a write of todo. If the todo changed
+// since it was loaded, it asks the user to reload or force the write
+// instead of failing.
+func saveTodo(todo *model.Todo, write func(todo *model.Todo) error) tea.Cmd {
+	var save func() tea.Msg
+	save = func() tea.Msg {
+		// A failed write may have changed todo already, e.g. Complete clears
+		// the recurrence, so a forced retry starts over from this copy
+		original := *todo
+		err := write(todo)
+		var conflict *storage.ConflictError
+		if errors.As(err, &conflict) {
+			return conflictMsg{
+				current: conflict.Current,
+				force: func() tea.Msg {
+					*todo = original
+					todo.Version = conflict.Current.Version
+					return save()
+				},
+			}
+		}
+		if err != nil {
+			return errMsg{err}
+		}
+		return todoUpdatedMsg{}
+	}
+	return save
+}
 
 // NewApp creates a new TUI application
 func NewApp(store storage.Storage) *App {
@@ -48,9 +104,60 @@ func NewApp(store storage.Storage) *App {
 	app.list = NewListView(store)
 	app.detail = NewDetailView()
 	app.input = NewInputView()
+	app.quick = NewQuickAddView()
 	return app
 }
 
+// SetWorkspaces lets the w key cycle through the named workspaces, opening
+// each with open. active is the workspace of the store passed to NewApp.
+func (a *App) SetWorkspaces(names []string, active string, open func(name string) (storage.Storage, error)) {
+	a.workspaces = names
+	a.openWorkspace = open
+	if len(names) > 1 {
+		a.list.workspace = active
+	}
+}
+
+// Close closes the storage of a workspace switched to in the TUI
+func (a *App) Close() error {
+	if a.owned == nil {
+		return nil
+	}
+	return a.owned.Close()
+}
+
+// switchWorkspace moves on to the next workspace, starting over with an
+// unfiltered list
+func (a *App) switchWorkspace() tea.Cmd {
+	if len(a.workspaces) < 2 {
+		return nil
+	}
+
+	next := a.workspaces[0]
+	for i, name := range a.workspaces {
+		if name == a.list.workspace && i+1 < len(a.workspaces) {
+			next = a.workspaces[i+1]
+		}
+	}
+
+	store, err := a.openWorkspace(next)
+	if err != nil {
+		a.err = err
+		return nil
+	}
+	if err := a.Close(); err != nil {
+		a.err = err
+	}
+	a.owned = store
+	a.store = store
+
+	a.list = NewListView(store)
+	a.list.SetSize(a.width, a.height-4)
+	a.list.workspace = next
+	a.status = "Switched to workspace " + next
+	return a.list.loadTodos()
+}
+
 // Init initializes the application
 func (a *App) Init() tea.Cmd {
 	return a.list.loadTodos()
@@ -71,6 +178,10 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.quitting = true
 			return a, tea.Quit
 		}
+		a.status = ""
+		if a.conflict != nil {
+			return a, a.resolveConflict(msg)
+		}
 
 	case errMsg:
 		a.err = msg.err
@@ -79,6 +190,34 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case todosLoadedMsg:
 		return a, nil
 
+	case conflictMsg:
+		a.conflict = &msg
+		return a, nil
+
+	case undoneMsg:
+		switch {
+		case len(msg.ops) == 0 && msg.redo:
+			a.status = "Nothing to redo"
+		case len(msg.ops) == 0:
+			a.status = "Nothing to undo"
+		case msg.redo:
+			a.status = "Redid: " + msg.ops[0].Description
+		default:
+			a.status = "Undid: " + msg.ops[0].Description
+		}
+		return a, a.list.loadTodos()
+
+	case todoRestoredMsg:
+		a.status = fmt.Sprintf("Restored #%d: %s", msg.todos[0].ID, msg.todos[0].Title)
+		if n := len(msg.todos) - 1; n > 0 {
+			a.status += fmt.Sprintf(" (and %d subtask(s))", n)
+		}
+		return a, tea.Batch(a.list.loadTrash(), a.list.loadTodos())
+
+	case quickAddedMsg:
+		a.status = fmt.Sprintf("Created todo #%d: %s", msg.todo.ID, msg.todo.Title)
+		return a, a.list.loadTodos()
+
 	case todoCreatedMsg, todoUpdatedMsg, todoDeletedMsg:
 		// Reload list after modifications
 		return a, a.list.loadTodos()
@@ -93,10 +232,14 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd = a.updateDetail(msg)
 	case ViewAdd, ViewEdit:
 		cmd = a.updateInput(msg)
+	case ViewQuickAdd:
+		cmd = a.updateQuickAdd(msg)
 	case ViewSearch:
 		cmd = a.updateSearch(msg)
 	case ViewTagFilter:
 		cmd = a.updateTagFilter(msg)
+	case ViewTrash:
+		cmd = a.updateTrash(msg)
 	}
 
 	return a, cmd
@@ -114,15 +257,39 @@ func (a *App) updateList(msg tea.Msg) tea.Cmd {
 			if todo := a.list.SelectedTodo(); todo != nil {
 				a.detail.SetTodo(todo)
 				a.view = ViewDetail
+				return a.detail.loadHistory(a.store)
 			}
 			return nil
 
 		case "n":
 			a.input.Reset()
+			if project := a.list.currentProject(); project != nil {
+				a.input.projectID = &project.ID
+			}
 			a.input.mode = InputModeAdd
 			a.view = ViewAdd
 			return a.input.Init()
 
+		case "a":
+			var projectID *int64
+			if project := a.list.currentProject(); project != nil {
+				projectID = &project.ID
+			}
+			a.view = ViewQuickAdd
+			return a.quick.Reset(projectID)
+
+		case "N":
+			if todo := a.list.SelectedTodo(); todo != nil {
+				a.input.Reset()
+				parentID := todo.ID
+				a.input.parentID = &parentID
+				a.input.projectID = todo.ProjectID
+				a.input.mode = InputModeAdd
+				a.view = ViewAdd
+				return a.input.Init()
+			}
+			return nil
+
 		case "e":
 			if todo := a.list.SelectedTodo(); todo != nil {
 				a.input.SetTodo(todo)
@@ -143,7 +310,10 @@ func (a *App) updateList(msg tea.Msg) tea.Cmd {
 			return a.list.loadTags()
 
 		case " ":
-			return a.list.toggleComplete()
+			return a.list.toggleComplete(false)
+
+		case "C":
+			return a.list.toggleComplete(true)
 
 		case "x":
 			a.list.toggleSelect()
@@ -152,8 +322,22 @@ func (a *App) updateList(msg tea.Msg) tea.Cmd {
 		case "D":
 			return a.list.deleteSelected()
 
+		case "T":
+			a.list.trashCursor = 0
+			a.view = ViewTrash
+			return a.list.loadTrash()
+
+		case "u":
+			return a.list.undo(false)
+
+		case "ctrl+r":
+			return a.list.undo(true)
+
 		case "p":
 			return a.list.cyclePriority()
+
+		case "w":
+			return a.switchWorkspace()
 		}
 	}
 
@@ -179,7 +363,7 @@ func (a *App) updateDetail(msg tea.Msg) tea.Cmd {
 
 		case " ":
 			if a.detail.todo != nil {
-				cmd := a.detail.toggleComplete(a.store)
+				cmd := a.detail.toggleComplete(a.store, false)
 				a.view = ViewList
 				return tea.Batch(cmd, a.list.loadTodos())
 			}
@@ -217,6 +401,26 @@ func (a *App) updateInput(msg tea.Msg) tea.Cmd {
 	return a.input.Update(msg)
 }
 
+func (a *App) updateQuickAdd(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			a.view = ViewList
+			return nil
+
+		case "enter":
+			cmd := a.quick.createTodo(a.store)
+			if cmd != nil {
+				a.view = ViewList
+			}
+			return cmd
+		}
+	}
+
+	return a.quick.Update(msg)
+}
+
 func (a *App) updateSearch(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -277,6 +481,50 @@ func (a *App) updateTagFilter(msg tea.Msg) tea.Cmd {
 	return nil
 }
 
+func (a *App) updateTrash(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "q":
+			a.view = ViewList
+			return nil
+
+		case "j", "down":
+			if a.list.trashCursor < len(a.list.trash)-1 {
+				a.list.trashCursor++
+			}
+			return nil
+
+		case "k", "up":
+			if a.list.trashCursor > 0 {
+				a.list.trashCursor--
+			}
+			return nil
+
+		case "r":
+			return a.list.restoreTrashed()
+		}
+	}
+
+	return nil
+}
+
+// resolveConflict handles the keys of the conflict prompt
+func (a *App) resolveConflict(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "r", "esc":
+		a.status = fmt.Sprintf("Reloaded #%d; your changes were discarded", a.conflict.current.ID)
+		a.conflict = nil
+		return a.list.loadTodos()
+
+	case "f":
+		force := a.conflict.force
+		a.conflict = nil
+		return force
+	}
+	return nil
+}
+
 // View renders the application
 func (a *App) View() string {
 	if a.quitting {
@@ -294,15 +542,26 @@ func (a *App) View() string {
 		content = a.input.View()
 	case ViewEdit:
 		content = a.input.View()
+	case ViewQuickAdd:
+		content = a.quick.View()
 	case ViewSearch:
 		content = a.list.ViewSearch()
 	case ViewTagFilter:
 		content = a.list.ViewTagFilter()
+	case ViewTrash:
+		content = a.list.ViewTrash()
 	}
 
+	if a.status != "" {
+		content += "\n" + successStyle.Render(a.status)
+	}
 	if a.err != nil {
 		content += "\n" + errorStyle.Render("Error: "+a.err.Error())
 	}
+	if a.conflict != nil {
+		content += "\n" + errorStyle.Render(fmt.Sprintf("Todo #%d was changed elsewhere since you opened it.", a.conflict.current.ID))
+		content += "\n" + helpStyle.Render("r: reload and discard your changes  f: force your changes")
+	}
 
 	return appStyle.Render(content)
 }
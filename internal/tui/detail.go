@@ -3,7 +3,6 @@ package tui
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -13,9 +12,10 @@ import (
 
 // DetailView displays a single todo's details
 type DetailView struct {
-	todo   *model.Todo
-	width  int
-	height int
+	todo    *model.Todo
+	history []storage.Event
+	width   int
+	height  int
 }
 
 // NewDetailView creates a new detail view
@@ -26,6 +26,28 @@ func NewDetailView() *DetailView {
 // SetTodo sets the todo to display
 func (d *DetailView) SetTodo(todo *model.Todo) {
 	d.todo = todo
+	d.history = nil
+}
+
+// historyLength is how many of the latest changes the detail view shows
+const historyLength = 8
+
+func (d *DetailView) loadHistory(store storage.Storage) tea.Cmd {
+	if d.todo == nil {
+		return nil
+	}
+
+	todo := d.todo
+	return func() tea.Msg {
+		events, err := store.History(todo.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		if d.todo == todo {
+			d.history = events
+		}
+		return historyLoadedMsg{}
+	}
 }
 
 // Update handles input for the detail view
@@ -33,25 +55,23 @@ func (d *DetailView) Update(msg tea.Msg) tea.Cmd {
 	return nil
 }
 
-func (d *DetailView) toggleComplete(store storage.Storage) tea.Cmd {
+func (d *DetailView) toggleComplete(store storage.Storage, cascade bool) tea.Cmd {
 	if d.todo == nil {
 		return nil
 	}
 
-	return func() tea.Msg {
-		d.todo.Completed = !d.todo.Completed
-		if d.todo.Completed {
-			now := time.Now().UTC()
-			d.todo.CompletedAt = &now
-		} else {
-			d.todo.CompletedAt = nil
-		}
-
-		if err := store.Update(d.todo); err != nil {
-			return errMsg{err}
-		}
-		return todoUpdatedMsg{}
+	if !d.todo.Completed {
+		return saveTodo(d.todo, func(todo *model.Todo) error {
+			_, err := storage.Complete(store, todo, cascade)
+			return err
+		})
 	}
+
+	return saveTodo(d.todo, func(todo *model.Todo) error {
+		todo.Completed = false
+		todo.CompletedAt = nil
+		return store.Update(todo)
+	})
 }
 
 // View renders the detail view
@@ -96,6 +116,13 @@ func (d *DetailView) View() string {
 		b.WriteString("\n")
 	}
 
+	// Project
+	if d.todo.Project != "" {
+		b.WriteString(labelStyle.Render("Project:"))
+		b.WriteString(projectStyle.Render(d.todo.Project))
+		b.WriteString("\n")
+	}
+
 	// Tags
 	if len(d.todo.Tags) > 0 {
 		b.WriteString(labelStyle.Render("Tags:"))
@@ -108,7 +135,7 @@ func (d *DetailView) View() string {
 	// Due date
 	if d.todo.DueDate != nil {
 		b.WriteString(labelStyle.Render("Due:"))
-		dueStr := d.todo.DueDate.Local().Format("2006-01-02 15:04")
+		dueStr := d.todo.FormatDue("2006-01-02")
 		if d.todo.IsOverdue() && !d.todo.Completed {
 			b.WriteString(overdueStyle.Render(dueStr + " (OVERDUE)"))
 		} else if d.todo.IsDueToday() {
@@ -121,6 +148,32 @@ func (d *DetailView) View() string {
 		b.WriteString("\n")
 	}
 
+	// Hierarchy
+	if d.todo.ParentID != nil {
+		b.WriteString(labelStyle.Render("Parent:"))
+		b.WriteString(valueStyle.Render(fmt.Sprintf("#%d", *d.todo.ParentID)))
+		b.WriteString("\n")
+	}
+	if d.todo.HasSubtasks() {
+		b.WriteString(labelStyle.Render("Subtasks:"))
+		b.WriteString(valueStyle.Render(d.todo.ProgressString()))
+		b.WriteString("\n")
+	}
+
+	// Dependencies
+	if d.todo.IsBlocked() {
+		b.WriteString(labelStyle.Render("Blocked:"))
+		b.WriteString(blockedStyle.Render(fmt.Sprintf("waiting on %d open todo(s)", d.todo.OpenBlockers)))
+		b.WriteString("\n")
+	}
+
+	// Recurrence
+	if rule := d.todo.RecurrenceRule(); rule != nil {
+		b.WriteString(labelStyle.Render("Repeats:"))
+		b.WriteString(valueStyle.Render(rule.Describe()))
+		b.WriteString("\n")
+	}
+
 	// Timestamps
 	b.WriteString("\n")
 	b.WriteString(labelStyle.Render("Created:"))
@@ -137,6 +190,25 @@ func (d *DetailView) View() string {
 		b.WriteString("\n")
 	}
 
+	// History
+	if len(d.history) > 0 {
+		b.WriteString("\n")
+		b.WriteString(sidebarTitleStyle.Render("History"))
+		b.WriteString("\n")
+
+		events := d.history
+		if len(events) > historyLength {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("  … %d earlier change(s), see 'todo log %d'", len(events)-historyLength, d.todo.ID)))
+			b.WriteString("\n")
+			events = events[len(events)-historyLength:]
+		}
+		for _, e := range events {
+			b.WriteString(helpStyle.Render(e.At.Local().Format("2006-01-02 15:04") + "  "))
+			b.WriteString(valueStyle.Render(e.Verb() + " " + e.Change()))
+			b.WriteString("\n")
+		}
+	}
+
 	// Help
 	b.WriteString("\n")
 	b.WriteString(helpStyle.Render("space: toggle complete  e: edit  q/esc: back"))
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -22,6 +23,8 @@ const (
 type InputView struct {
 	mode       InputMode
 	todo       *model.Todo
+	parentID   *int64 // parent of a new subtask
+	projectID  *int64 // project of a new todo
 	inputs     []textinput.Model
 	focusIndex int
 	labels     []string
@@ -54,7 +57,7 @@ func NewInputView() *InputView {
 		case inputTags:
 			ti.Placeholder = "#work #urgent"
 		case inputDueDate:
-			ti.Placeholder = "2026-02-14 or today/tomorrow"
+			ti.Placeholder = "2026-02-14, tomorrow 9am, next friday"
 		case inputPriority:
 			ti.Placeholder = "1-5 (1=urgent, 5=lowest)"
 			ti.CharLimit = 1
@@ -73,6 +76,8 @@ func NewInputView() *InputView {
 // Reset clears the input form
 func (v *InputView) Reset() {
 	v.todo = nil
+	v.parentID = nil
+	v.projectID = nil
 	v.focusIndex = 0
 	v.err = ""
 	for i := range v.inputs {
@@ -93,7 +98,7 @@ func (v *InputView) SetTodo(todo *model.Todo) {
 	v.inputs[inputTags].SetValue(strings.Join(todo.Tags, " "))
 
 	if todo.DueDate != nil {
-		v.inputs[inputDueDate].SetValue(todo.DueDate.Format("2006-01-02"))
+		v.inputs[inputDueDate].SetValue(todo.FormatDue("2006-01-02"))
 	} else {
 		v.inputs[inputDueDate].SetValue("")
 	}
@@ -165,17 +170,20 @@ func (v *InputView) createTodo(store storage.Storage) tea.Cmd {
 		Title:       title,
 		Description: strings.TrimSpace(v.inputs[inputDescription].Value()),
 		Tags:        storage.ParseTags(v.inputs[inputTags].Value()),
+		ParentID:    v.parentID,
+		ProjectID:   v.projectID,
 	}
 
 	// Parse due date
 	dueDateStr := strings.TrimSpace(v.inputs[inputDueDate].Value())
 	if dueDateStr != "" {
-		dueDate, err := storage.ParseDueDate(dueDateStr)
+		dueDate, hasTime, err := storage.ParseDue(dueDateStr)
 		if err != nil {
 			v.err = "Invalid due date format"
 			return nil
 		}
 		todo.DueDate = dueDate
+		todo.DueHasTime = hasTime
 	}
 
 	// Parse priority
@@ -215,14 +223,16 @@ func (v *InputView) updateTodo(store storage.Storage) tea.Cmd {
 	// Parse due date
 	dueDateStr := strings.TrimSpace(v.inputs[inputDueDate].Value())
 	if dueDateStr != "" {
-		dueDate, err := storage.ParseDueDate(dueDateStr)
+		dueDate, hasTime, err := storage.ParseDue(dueDateStr)
 		if err != nil {
 			v.err = "Invalid due date format"
 			return nil
 		}
 		v.todo.DueDate = dueDate
+		v.todo.DueHasTime = hasTime
 	} else {
 		v.todo.DueDate = nil
+		v.todo.DueHasTime = false
 	}
 
 	// Parse priority
@@ -238,13 +248,7 @@ func (v *InputView) updateTodo(store storage.Storage) tea.Cmd {
 		v.todo.Priority = 0
 	}
 
-	todo := v.todo
-	return func() tea.Msg {
-		if err := store.Update(todo); err != nil {
-			return errMsg{err}
-		}
-		return todoUpdatedMsg{}
-	}
+	return saveTodo(v.todo, store.Update)
 }
 
 // View renders the input form
@@ -255,6 +259,8 @@ func (v *InputView) View() string {
 	title := "New Todo"
 	if v.mode == InputModeEdit {
 		title = "Edit Todo"
+	} else if v.parentID != nil {
+		title = fmt.Sprintf("New Subtask of #%d", *v.parentID)
 	}
 	b.WriteString(titleStyle.Render(title))
 	b.WriteString("\n\n")
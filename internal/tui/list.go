@@ -3,7 +3,6 @@ package tui
 import (
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,7 +15,10 @@ import (
 // ListView displays a list of todos
 type ListView struct {
 	store        storage.Storage
-	todos        []model.Todo
+	all          []model.Todo // as loaded, in sort order
+	todos        []model.Todo // visible rows in tree order
+	depths       []int
+	collapsed    map[int64]bool
 	cursor       int
 	selected     map[int64]bool
 	filter       storage.Filter
@@ -24,10 +26,18 @@ type ListView struct {
 	height       int
 	searchMode   bool
 	searchInput  textinput.Model
-	allTags      []string
+	allTags      []storage.TagCount
 	selectedTags map[string]bool
 	tagCursor    int
 	showPending  bool
+	projects     []storage.ProjectStats
+	projectIndex int // 0 = all projects, otherwise projects[projectIndex-1]
+	views        []storage.View
+	viewIndex    int // 0 = default list, otherwise views[viewIndex-1]
+	dueIndex     int // 0 = any due date, otherwise duePresets[dueIndex-1]
+	trash        []model.Todo
+	trashCursor  int
+	workspace    string // shown in the title when there are several
 }
 
 // NewListView creates a new list view
@@ -37,19 +47,33 @@ func NewListView(store storage.Storage) *ListView {
 	ti.CharLimit = 100
 	ti.Width = 30
 
-	pending := false
 	return &ListView{
 		store:        store,
 		selected:     make(map[int64]bool),
 		selectedTags: make(map[string]bool),
+		collapsed:    make(map[int64]bool),
 		searchInput:  ti,
 		showPending:  true,
-		filter: storage.Filter{
-			Completed: &pending,
-		},
+		filter:       defaultFilter(),
 	}
 }
 
+// duePresets are the due date filters the d key cycles through
+var duePresets = []struct {
+	due   *storage.TimeRange
+	noDue bool
+}{
+	{due: &storage.TimeRange{To: "eow"}},
+	{due: &storage.TimeRange{To: "eom"}},
+	{noDue: true},
+}
+
+// defaultFilter is the filter of the default tab: pending todos
+func defaultFilter() storage.Filter {
+	pending := false
+	return storage.Filter{Completed: &pending}
+}
+
 // SetSize sets the view dimensions
 func (l *ListView) SetSize(width, height int) {
 	l.width = width
@@ -62,14 +86,78 @@ func (l *ListView) loadTodos() tea.Cmd {
 		if err != nil {
 			return errMsg{err}
 		}
-		l.todos = todos
-		if l.cursor >= len(todos) {
-			l.cursor = max(0, len(todos)-1)
+		l.all = todos
+		l.rebuildTree()
+
+		projects, err := l.store.ProjectStats()
+		if err != nil {
+			return errMsg{err}
+		}
+		l.projects = projects
+		if l.projectIndex > len(projects) {
+			l.projectIndex = 0
+			l.filter.Project = ""
+		}
+
+		views, err := l.store.ListViews()
+		if err != nil {
+			return errMsg{err}
+		}
+		l.views = views
+		if l.viewIndex > len(views) {
+			l.viewIndex = 0
 		}
 		return todosLoadedMsg{}
 	}
 }
 
+// rebuildTree lays out the loaded todos as a tree, hiding collapsed subtasks
+func (l *ListView) rebuildTree() {
+	items := model.BuildTree(l.all, l.collapsed)
+	l.todos = make([]model.Todo, len(items))
+	l.depths = make([]int, len(items))
+	for i, item := range items {
+		l.todos[i] = item.Todo
+		l.depths[i] = item.Depth
+	}
+	if l.cursor >= len(l.todos) {
+		l.cursor = max(0, len(l.todos)-1)
+	}
+}
+
+// collapse hides the selected todo's subtasks, or jumps to its parent
+func (l *ListView) collapse() {
+	todo := l.SelectedTodo()
+	if todo == nil {
+		return
+	}
+
+	if todo.HasSubtasks() && !l.collapsed[todo.ID] {
+		l.collapsed[todo.ID] = true
+		l.rebuildTree()
+		return
+	}
+
+	if todo.ParentID != nil {
+		for i := range l.todos {
+			if l.todos[i].ID == *todo.ParentID {
+				l.cursor = i
+				return
+			}
+		}
+	}
+}
+
+// expand shows the selected todo's subtasks
+func (l *ListView) expand() {
+	todo := l.SelectedTodo()
+	if todo == nil || !l.collapsed[todo.ID] {
+		return
+	}
+	delete(l.collapsed, todo.ID)
+	l.rebuildTree()
+}
+
 func (l *ListView) loadTags() tea.Cmd {
 	return func() tea.Msg {
 		tags, err := l.store.GetAllTags()
@@ -81,6 +169,40 @@ func (l *ListView) loadTags() tea.Cmd {
 	}
 }
 
+func (l *ListView) loadTrash() tea.Cmd {
+	return func() tea.Msg {
+		trash, err := l.store.List(storage.Filter{
+			Trashed:   true,
+			SortBy:    storage.SortByDeleted,
+			SortOrder: storage.SortDesc,
+		})
+		if err != nil {
+			return errMsg{err}
+		}
+		l.trash = trash
+		if l.trashCursor >= len(l.trash) {
+			l.trashCursor = max(0, len(l.trash)-1)
+		}
+		return nil
+	}
+}
+
+// restoreTrashed brings the highlighted todo in the trash back
+func (l *ListView) restoreTrashed() tea.Cmd {
+	if l.trashCursor < 0 || l.trashCursor >= len(l.trash) {
+		return nil
+	}
+	id := l.trash[l.trashCursor].ID
+
+	return func() tea.Msg {
+		restored, err := l.store.Restore(id)
+		if err != nil {
+			return errMsg{err}
+		}
+		return todoRestoredMsg{todos: restored}
+	}
+}
+
 // SelectedTodo returns the currently highlighted todo
 func (l *ListView) SelectedTodo() *model.Todo {
 	if l.cursor < 0 || l.cursor >= len(l.todos) {
@@ -89,25 +211,84 @@ func (l *ListView) SelectedTodo() *model.Todo {
 	return &l.todos[l.cursor]
 }
 
-func (l *ListView) toggleComplete() tea.Cmd {
-	todo := l.SelectedTodo()
-	if todo == nil {
-		return nil
+// toggleComplete completes the targeted todos, or reopens them if they are
+// all completed already
+func (l *ListView) toggleComplete(cascade bool) tea.Cmd {
+	complete := false
+	for _, todo := range l.targets() {
+		if !todo.Completed {
+			complete = true
+		}
 	}
 
-	return func() tea.Msg {
-		todo.Completed = !todo.Completed
-		if todo.Completed {
-			now := time.Now().UTC()
-			todo.CompletedAt = &now
-		} else {
+	if !complete {
+		return l.applyToTargets("reopen", todoUpdatedMsg{}, func(tx storage.Storage, todo *model.Todo) error {
+			todo.Completed = false
 			todo.CompletedAt = nil
+			return tx.Update(todo)
+		})
+	}
+
+	return l.applyToTargets("complete", todoUpdatedMsg{}, func(tx storage.Storage, todo *model.Todo) error {
+		if todo.Completed {
+			return nil
+		}
+		_, err := storage.Complete(tx, todo, cascade)
+		return err
+	})
+}
+
+// targets returns the todos an action applies to: the selected ones, or the
+// highlighted one when nothing is selected
+func (l *ListView) targets() []model.Todo {
+	if len(l.selected) == 0 {
+		if todo := l.SelectedTodo(); todo != nil {
+			return []model.Todo{*todo}
+		}
+		return nil
+	}
+
+	var targets []model.Todo
+	for _, todo := range l.all {
+		if l.selected[todo.ID] {
+			targets = append(targets, todo)
 		}
+	}
+	return targets
+}
+
+// applyToTargets runs fn on every targeted todo in one transaction, so that
+// a failure leaves all of them unchanged, and clears the selection
+func (l *ListView) applyToTargets(verb string, done tea.Msg, fn func(tx storage.Storage, todo *model.Todo) error) tea.Cmd {
+	targets := l.targets()
+	if len(targets) == 0 {
+		return nil
+	}
+
+	description := ""
+	if len(targets) > 1 {
+		description = fmt.Sprintf("%s %d todos", verb, len(targets))
+	}
 
-		if err := l.store.Update(todo); err != nil {
+	return func() tea.Msg {
+		err := l.store.Batch(description, func(tx storage.Storage) error {
+			for _, target := range targets {
+				// Reload: an earlier target may have changed this one
+				todo, err := tx.GetByID(target.ID)
+				if err != nil {
+					return err
+				}
+				if err := fn(tx, todo); err != nil {
+					return fmt.Errorf("#%d %s: %w", todo.ID, todo.Title, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
 			return errMsg{err}
 		}
-		return todoUpdatedMsg{}
+		l.selected = make(map[int64]bool)
+		return done
 	}
 }
 
@@ -125,45 +306,110 @@ func (l *ListView) toggleSelect() {
 }
 
 func (l *ListView) deleteSelected() tea.Cmd {
-	if len(l.selected) == 0 {
-		// Delete current item if nothing selected
-		todo := l.SelectedTodo()
-		if todo == nil {
-			return nil
-		}
-		return func() tea.Msg {
-			if err := l.store.Delete(todo.ID); err != nil {
-				return errMsg{err}
-			}
-			return todoDeletedMsg{}
-		}
-	}
+	return l.applyToTargets("delete", todoDeletedMsg{}, func(tx storage.Storage, todo *model.Todo) error {
+		return tx.Delete(todo.ID)
+	})
+}
 
-	// Delete all selected
+// undo reverts (or with redo, reapplies) the last change
+func (l *ListView) undo(redo bool) tea.Cmd {
 	return func() tea.Msg {
-		for id := range l.selected {
-			if err := l.store.Delete(id); err != nil {
-				return errMsg{err}
-			}
+		replay := l.store.Undo
+		if redo {
+			replay = l.store.Redo
 		}
-		l.selected = make(map[int64]bool)
-		return todoDeletedMsg{}
+
+		ops, err := replay(1)
+		if err != nil {
+			return errMsg{err}
+		}
+		return undoneMsg{ops: ops, redo: redo}
 	}
 }
 
+// cyclePriority moves the highlighted todo to the next priority, and any
+// other selected todos along with it
 func (l *ListView) cyclePriority() tea.Cmd {
 	todo := l.SelectedTodo()
 	if todo == nil {
 		return nil
 	}
+	priority := (todo.Priority % 5) + 1
 
-	return func() tea.Msg {
-		todo.Priority = (todo.Priority % 5) + 1
-		if err := l.store.Update(todo); err != nil {
-			return errMsg{err}
+	return l.applyToTargets("prioritize", todoUpdatedMsg{}, func(tx storage.Storage, todo *model.Todo) error {
+		todo.Priority = priority
+		return tx.Update(todo)
+	})
+}
+
+// currentProject returns the project the list is filtered by, if any
+func (l *ListView) currentProject() *model.Project {
+	if l.projectIndex == 0 || l.projectIndex > len(l.projects) {
+		return nil
+	}
+	return &l.projects[l.projectIndex-1].Project
+}
+
+// cycleProject moves the project filter through the sidebar entries
+func (l *ListView) cycleProject(delta int) tea.Cmd {
+	if len(l.projects) == 0 {
+		return nil
+	}
+
+	n := len(l.projects) + 1
+	l.projectIndex = ((l.projectIndex+delta)%n + n) % n
+	l.filter.Project = ""
+	if project := l.currentProject(); project != nil {
+		l.filter.Project = project.Name
+	}
+	l.cursor = 0
+	return l.loadTodos()
+}
+
+// cycleDue moves the due date filter through duePresets
+func (l *ListView) cycleDue() tea.Cmd {
+	l.dueIndex = (l.dueIndex + 1) % (len(duePresets) + 1)
+	l.filter.DueRange = nil
+	l.filter.NoDue = false
+	if l.dueIndex > 0 {
+		preset := duePresets[l.dueIndex-1]
+		l.filter.DueRange = preset.due
+		l.filter.NoDue = preset.noDue
+	}
+	l.cursor = 0
+	return l.loadTodos()
+}
+
+// cycleView switches to the next or previous saved view tab
+func (l *ListView) cycleView(delta int) tea.Cmd {
+	if len(l.views) == 0 {
+		return nil
+	}
+
+	n := len(l.views) + 1
+	l.viewIndex = ((l.viewIndex+delta)%n + n) % n
+
+	l.filter = defaultFilter()
+	if l.viewIndex > 0 {
+		l.filter = l.views[l.viewIndex-1].Filter
+	}
+	l.showPending = l.filter.Completed != nil && !*l.filter.Completed
+	l.dueIndex = 0
+
+	l.projectIndex = 0
+	for i, st := range l.projects {
+		if strings.EqualFold(st.Project.Name, l.filter.Project) {
+			l.projectIndex = i + 1
 		}
-		return todoUpdatedMsg{}
 	}
+
+	l.selectedTags = make(map[string]bool)
+	for _, tag := range l.filter.Tags {
+		l.selectedTags[tag] = true
+	}
+	l.searchInput.SetValue("")
+	l.cursor = 0
+	return l.loadTodos()
 }
 
 func (l *ListView) toggleTagSelection() {
@@ -175,7 +421,7 @@ func (l *ListView) toggleTagSelection() {
 
 	idx := l.tagCursor - 1
 	if idx < len(l.allTags) {
-		tag := l.allTags[idx]
+		tag := l.allTags[idx].Tag
 		if l.selectedTags[tag] {
 			delete(l.selectedTags, tag)
 		} else {
@@ -215,6 +461,27 @@ func (l *ListView) Update(msg tea.Msg) tea.Cmd {
 		case "G":
 			l.cursor = max(0, len(l.todos)-1)
 
+		case "h", "left":
+			l.collapse()
+
+		case "l", "right":
+			l.expand()
+
+		case "[":
+			return l.cycleProject(-1)
+
+		case "]":
+			return l.cycleProject(1)
+
+		case "v":
+			return l.cycleView(1)
+
+		case "V":
+			return l.cycleView(-1)
+
+		case "d":
+			return l.cycleDue()
+
 		case "tab":
 			// Toggle between pending/all
 			if l.showPending {
@@ -243,14 +510,26 @@ func (l *ListView) UpdateSearch(msg tea.Msg) tea.Cmd {
 func (l *ListView) View() string {
 	var b strings.Builder
 
+	if len(l.views) > 0 {
+		b.WriteString(l.renderViewTabs())
+		b.WriteString("\n\n")
+	}
+
 	// Title
 	title := "TODO List"
+	if l.workspace != "" {
+		title = "[" + l.workspace + "] " + title
+	}
+	if l.filter.Project != "" {
+		title += " +" + l.filter.Project
+	}
 	if l.filter.Search != "" {
 		title += fmt.Sprintf(" (search: %s)", l.filter.Search)
 	}
 	if len(l.filter.Tags) > 0 {
 		title += fmt.Sprintf(" (tags: %s)", strings.Join(l.filter.Tags, ", "))
 	}
+	title += rangeTitle(l.filter)
 	if !l.showPending {
 		title += " [ALL]"
 	}
@@ -262,6 +541,9 @@ func (l *ListView) View() string {
 	} else {
 		// Calculate visible items
 		visibleHeight := l.height - 6
+		if len(l.views) > 0 {
+			visibleHeight -= 2
+		}
 		if visibleHeight < 1 {
 			visibleHeight = 10
 		}
@@ -278,7 +560,7 @@ func (l *ListView) View() string {
 
 		for i := start; i < end; i++ {
 			todo := l.todos[i]
-			b.WriteString(l.renderTodoItem(todo, i == l.cursor))
+			b.WriteString(l.renderTodoItem(todo, l.depths[i], i == l.cursor))
 			b.WriteString("\n")
 		}
 	}
@@ -293,13 +575,77 @@ func (l *ListView) View() string {
 
 	// Help
 	b.WriteString("\n")
-	help := "j/k:navigate  space:toggle  n:new  e:edit  /:search  t:tags  D:delete  tab:all/pending  q:quit"
+	help := "j/k:navigate  h/l:fold  space:toggle  n:new  a:quick add  N:subtask  e:edit  /:search  t:tags  [/]:project  v:view  d:due  D:delete  T:trash  u:undo  tab:all/pending  q:quit"
+	if l.workspace != "" {
+		help = strings.Replace(help, "q:quit", "w:workspace  q:quit", 1)
+	}
 	b.WriteString(helpStyle.Render(help))
 
-	return b.String()
+	if len(l.projects) == 0 {
+		return b.String()
+	}
+	main := b.String()
+	return lipgloss.JoinHorizontal(lipgloss.Top, l.renderProjectSidebar(lipgloss.Height(main)), main)
+}
+
+func (l *ListView) renderViewTabs() string {
+	tabs := make([]string, 0, len(l.views)+1)
+	tab := func(index int, name string) {
+		style := tabStyle
+		if index == l.viewIndex {
+			style = activeTabStyle
+		}
+		tabs = append(tabs, style.Render(name))
+	}
+
+	tab(0, "Default")
+	for i, v := range l.views {
+		tab(i+1, "@"+v.Name)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, tabs...)
 }
 
-func (l *ListView) renderTodoItem(todo model.Todo, selected bool) string {
+func (l *ListView) renderProjectSidebar(height int) string {
+	var b strings.Builder
+
+	b.WriteString(sidebarTitleStyle.Render("Projects"))
+	b.WriteString("\n\n")
+
+	entry := func(index int, name string, counts string) {
+		style := sidebarItemStyle
+		if index == l.projectIndex {
+			style = sidebarActiveStyle
+		}
+		b.WriteString(style.Render(name))
+		if counts != "" {
+			b.WriteString(" " + counts)
+		}
+		b.WriteString("\n")
+	}
+
+	entry(0, "All", "")
+	for i, st := range l.projects {
+		counts := progressStyle.Render(fmt.Sprintf("%d", st.Open))
+		if st.Overdue > 0 {
+			counts += " " + overdueStyle.Render(fmt.Sprintf("%d!", st.Overdue))
+		}
+		entry(i+1, truncate(st.Project.Name, 16), counts)
+	}
+
+	return sidebarStyle.Height(height).Render(b.String())
+}
+
+// truncate shortens s to n characters, ending it in "..." if cut
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+func (l *ListView) renderTodoItem(todo model.Todo, depth int, selected bool) string {
 	var parts []string
 
 	// Selection indicator
@@ -311,6 +657,17 @@ func (l *ListView) renderTodoItem(todo model.Todo, selected bool) string {
 		parts = append(parts, unselectedIndicator)
 	}
 
+	// Tree indentation and fold marker
+	parts = append(parts, strings.Repeat("  ", depth))
+	switch {
+	case todo.HasSubtasks() && l.collapsed[todo.ID]:
+		parts = append(parts, helpStyle.Render("▸ "))
+	case todo.HasSubtasks():
+		parts = append(parts, helpStyle.Render("▾ "))
+	default:
+		parts = append(parts, "  ")
+	}
+
 	// Checkbox
 	if todo.Completed {
 		parts = append(parts, checkedBox)
@@ -337,12 +694,27 @@ func (l *ListView) renderTodoItem(todo model.Todo, selected bool) string {
 	}
 	parts = append(parts, " "+titleStyle.Render(title))
 
+	// Dependencies
+	if todo.IsBlocked() && !todo.Completed {
+		parts = append(parts, " "+blockedStyle.Render("⊘ blocked"))
+	}
+
+	// Subtask progress
+	if todo.HasSubtasks() {
+		parts = append(parts, " "+progressStyle.Render(fmt.Sprintf("%d/%d", todo.SubtaskDone, todo.SubtaskTotal)))
+	}
+
 	// Due date
 	if todo.DueDate != nil {
 		dueStr := formatDue(todo)
 		parts = append(parts, " "+dueStr)
 	}
 
+	// Project (redundant when the list is already filtered by it)
+	if todo.Project != "" && l.filter.Project == "" {
+		parts = append(parts, " "+projectStyle.Render("+"+todo.Project))
+	}
+
 	// Tags (show first 2)
 	if len(todo.Tags) > 0 {
 		tagsToShow := todo.Tags
@@ -362,16 +734,45 @@ func formatDue(todo model.Todo) string {
 		return ""
 	}
 
+	at := ""
+	if todo.DueHasTime {
+		at = todo.DueDate.Local().Format(" 15:04")
+	}
+
 	if todo.IsOverdue() && !todo.Completed {
-		return overdueStyle.Render(todo.DueDate.Format("Jan 2") + "!")
+		return overdueStyle.Render(todo.FormatDue("Jan 2") + "!")
 	}
 	if todo.IsDueToday() {
-		return dueTodayStyle.Render("today")
+		return dueTodayStyle.Render("today" + at)
 	}
 	if todo.IsDueTomorrow() {
-		return dueNormalStyle.Render("tomorrow")
+		return dueNormalStyle.Render("tomorrow" + at)
 	}
-	return dueNormalStyle.Render(todo.DueDate.Format("Jan 2"))
+	return dueNormalStyle.Render(todo.FormatDue("Jan 2"))
+}
+
+// rangeTitle describes the date range filters of f for the list title
+func rangeTitle(f storage.Filter) string {
+	var parts []string
+	if f.DueRange != nil {
+		parts = append(parts, "due "+f.DueRange.String())
+	}
+	if f.NoDue {
+		parts = append(parts, "no due date")
+	}
+	if f.CreatedRange != nil {
+		parts = append(parts, "created "+f.CreatedRange.String())
+	}
+	if f.UpdatedRange != nil {
+		parts = append(parts, "updated "+f.UpdatedRange.String())
+	}
+	if f.CompletedRange != nil {
+		parts = append(parts, "completed "+f.CompletedRange.String())
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
 }
 
 // ViewSearch renders the search mode
@@ -384,7 +785,7 @@ func (l *ListView) ViewSearch() string {
 	b.WriteString(focusedInputStyle.Render(l.searchInput.View()))
 	b.WriteString("\n\n")
 
-	b.WriteString(helpStyle.Render("Enter: search  Esc: cancel"))
+	b.WriteString(helpStyle.Render(`Enter: search  Esc: cancel  "phrase"  prefix*  -exclude  a OR b`))
 
 	return b.String()
 }
@@ -409,16 +810,17 @@ func (l *ListView) ViewTagFilter() string {
 	b.WriteString(fmt.Sprintf("%s%s All tags\n", indicator, checkbox))
 
 	// Individual tags
-	for i, tag := range l.allTags {
+	for i, tc := range l.allTags {
 		indicator := "  "
 		if l.tagCursor == i+1 {
 			indicator = "> "
 		}
 		checkbox := uncheckedBox
-		if l.selectedTags[tag] {
+		if l.selectedTags[tc.Tag] {
 			checkbox = checkedBox
 		}
-		b.WriteString(fmt.Sprintf("%s%s %s\n", indicator, checkbox, tagStyle.Render(tag)))
+		b.WriteString(fmt.Sprintf("%s%s %s %s\n", indicator, checkbox, tagStyle.Render(tc.Tag),
+			progressStyle.Render(fmt.Sprintf("(%d)", tc.Count))))
 	}
 
 	b.WriteString("\n")
@@ -427,6 +829,33 @@ func (l *ListView) ViewTagFilter() string {
 	return b.String()
 }
 
+// ViewTrash renders the deleted todos
+func (l *ListView) ViewTrash() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Trash"))
+	b.WriteString("\n\n")
+
+	if len(l.trash) == 0 {
+		b.WriteString(helpStyle.Render("The trash is empty."))
+		b.WriteString("\n")
+	}
+
+	for i, todo := range l.trash {
+		indicator := "  "
+		if l.trashCursor == i {
+			indicator = "> "
+		}
+		b.WriteString(fmt.Sprintf("%s#%-4d %s %s\n", indicator, todo.ID, truncate(todo.Title, 50),
+			progressStyle.Render("deleted "+todo.DeletedAt.Local().Format("Jan 2 15:04"))))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("j/k: navigate  r: restore  esc: back"))
+
+	return b.String()
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"todo_cli/internal/model"
+	"todo_cli/internal/storage"
+)
+
+// QuickAddView adds a todo from a single line, see storage.ParseQuickAdd
+type QuickAddView struct {
+	input     textinput.Model
+	projectID *int64 // project of the new todo unless the line names one
+}
+
+// NewQuickAddView creates a new quick-add bar
+func NewQuickAddView() *QuickAddView {
+	ti := textinput.New()
+	ti.Placeholder = "Ship release notes tomorrow 3pm #work !2 +website"
+	ti.CharLimit = 200
+	ti.Width = 60
+
+	return &QuickAddView{input: ti}
+}
+
+// Reset clears the bar and focuses it
+func (v *QuickAddView) Reset(projectID *int64) tea.Cmd {
+	v.projectID = projectID
+	v.input.SetValue("")
+	return v.input.Focus()
+}
+
+// Update handles typing into the bar
+func (v *QuickAddView) Update(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	v.input, cmd = v.input.Update(msg)
+	return cmd
+}
+
+// createTodo creates the todo described by the line. It returns nil while
+// the line can't be parsed, which View shows.
+func (v *QuickAddView) createTodo(store storage.Storage) tea.Cmd {
+	todo, err := storage.ParseQuickAdd(v.input.Value(), storage.QuickAddOptions{})
+	if err != nil {
+		return nil
+	}
+	todo.ProjectID = v.projectID
+
+	return func() tea.Msg {
+		if todo.Project != "" {
+			project, err := store.GetProject(todo.Project)
+			if err != nil {
+				return errMsg{err}
+			}
+			todo.ProjectID = &project.ID
+		}
+		if err := store.Create(todo); err != nil {
+			return errMsg{err}
+		}
+		return quickAddedMsg{todo}
+	}
+}
+
+// View renders the bar with what the line parses to, so mistakes show
+// before the todo is added
+func (v *QuickAddView) View() string {
+	var b strings.Builder
+
+	b.WriteString(titleStyle.Render("Quick Add"))
+	b.WriteString("\n\n")
+
+	b.WriteString(focusedInputStyle.Render(v.input.View()))
+	b.WriteString("\n\n")
+
+	if strings.TrimSpace(v.input.Value()) != "" {
+		todo, err := storage.ParseQuickAdd(v.input.Value(), storage.QuickAddOptions{})
+		if err != nil {
+			b.WriteString(errorStyle.Render("Error: " + err.Error()))
+		} else {
+			b.WriteString(quickAddPreview(todo))
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(helpStyle.Render("#tag  !1-!5 priority  +project  due date at the end  enter: add  esc: cancel"))
+
+	return b.String()
+}
+
+// quickAddPreview lists the fields of a parsed line
+func quickAddPreview(todo *model.Todo) string {
+	var b strings.Builder
+
+	row := func(label, value string) {
+		b.WriteString(inputLabelStyle.Render(fmt.Sprintf("%-9s", label)))
+		b.WriteString(value)
+		b.WriteString("\n")
+	}
+
+	row("Title", todo.Title)
+	if todo.DueDate != nil {
+		row("Due", todo.FormatDue("Mon Jan 2, 2006"))
+	}
+	if len(todo.Tags) > 0 {
+		row("Tags", strings.Join(todo.Tags, " "))
+	}
+	if todo.Priority > 0 {
+		row("Priority", todo.PriorityString())
+	}
+	if todo.Project != "" {
+		row("Project", todo.Project)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -68,6 +68,47 @@ var (
 	dueNormalStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("252"))
 
+	// Project styles
+	projectStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("176"))
+
+	sidebarStyle = lipgloss.NewStyle().
+			Width(24).
+			MarginRight(2).
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderRight(true).
+			BorderForeground(secondaryColor)
+
+	sidebarTitleStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(primaryColor)
+
+	sidebarItemStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("252"))
+
+	sidebarActiveStyle = lipgloss.NewStyle().
+				Foreground(primaryColor).
+				Bold(true)
+
+	// Saved view tabs
+	tabStyle = lipgloss.NewStyle().
+			Foreground(mutedColor).
+			Padding(0, 1)
+
+	activeTabStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("15")).
+			Background(secondaryColor).
+			Bold(true).
+			Padding(0, 1)
+
+	// Dependency marker
+	blockedStyle = lipgloss.NewStyle().
+			Foreground(warningColor)
+
+	// Subtask progress
+	progressStyle = lipgloss.NewStyle().
+			Foreground(secondaryColor)
+
 	// Detail view styles
 	labelStyle = lipgloss.NewStyle().
 			Foreground(secondaryColor).